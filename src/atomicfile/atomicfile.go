@@ -0,0 +1,97 @@
+// Package atomicfile writes files so that readers see either the old or the
+// new content, never a half-written file
+package atomicfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes a file by writing to a temporary file in the same
+// directory and renaming it over the original. The directory is created if
+// it doesn't exist. Existing files keep their permissions, and perm is only
+// used for new files. If path is a symlink, the file it points to is written
+// and the symlink is left as it is.
+func WriteFile(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	return writeFile(path, perm, true, write)
+}
+
+// WriteFileWithPerm is like WriteFile, but existing files get their
+// permissions set to perm as well
+func WriteFileWithPerm(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	return writeFile(path, perm, false, write)
+}
+
+func writeFile(path string, perm os.FileMode, keepPerm bool, write func(w io.Writer) error) error {
+	path, err := resolveSymlinks(path)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && keepPerm {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	// a no-op once the file has been renamed
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := write(tmp); err != nil {
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return fmt.Errorf("failed to set permissions of %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	// make the rename survive a crash. Directories can't be synced on all
+	// platforms, so this is best effort.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
+
+// resolveSymlinks follows the symlinks in path to the file they point to,
+// which doesn't have to exist yet
+func resolveSymlinks(path string) (string, error) {
+	// the same limit as Linux, to not loop forever on symlinks pointing at
+	// each other
+	for i := 0; i < 40; i++ {
+		info, err := os.Lstat(path)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read symlink %s: %w", path, err)
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("too many levels of symlinks in %s", path)
+}
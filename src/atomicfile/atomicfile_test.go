@@ -0,0 +1,107 @@
+package atomicfile_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/atomicfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "some-dir", "file.txt")
+
+	err := atomicfile.WriteFile(path, 0644, func(w io.Writer) error {
+		_, err := io.WriteString(w, "content")
+		return err
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestWriteFile_KeepsOldContentOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("old content"), 0644))
+
+	err := atomicfile.WriteFile(path, 0644, func(w io.Writer) error {
+		io.WriteString(w, "half of the new")
+		return errors.New("some error")
+	})
+	assert.Error(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old content", string(content))
+
+	// the temporary file is removed
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestWriteFile_KeepsPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("old content"), 0600))
+	// os.WriteFile applies the umask to the permissions
+	require.NoError(t, os.Chmod(path, 0600))
+
+	err := atomicfile.WriteFile(path, 0644, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new content")
+		return err
+	})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestWriteFileWithPerm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("old content"), 0600))
+	require.NoError(t, os.Chmod(path, 0600))
+
+	err := atomicfile.WriteFileWithPerm(path, 0644, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new content")
+		return err
+	})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestWriteFile_WritesThroughSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "shared", "file.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0755))
+	require.NoError(t, os.WriteFile(target, []byte("old content"), 0644))
+	link := filepath.Join(dir, "link.txt")
+	require.NoError(t, os.Symlink(filepath.Join("shared", "file.txt"), link))
+
+	err := atomicfile.WriteFile(link, 0644, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new content")
+		return err
+	})
+	require.NoError(t, err)
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink, "the symlink is kept")
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "new content", string(content))
+}
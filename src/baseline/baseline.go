@@ -0,0 +1,59 @@
+// Package baseline stores the problems of a project at a point in time, so
+// that only new problems fail the check. This makes it possible to adopt the
+// tool in projects that already have many unknown or disallowed licenses.
+package baseline
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/eriklarko/license-checker/src/atomicfile"
+	"github.com/eriklarko/license-checker/src/checker"
+	"gopkg.in/yaml.v3"
+)
+
+// Baseline is a snapshot of the problems in a report
+type Baseline struct {
+	CreatedAt time.Time               `yaml:"created-at"`
+	Problems  []checker.BaselineEntry `yaml:"problems"`
+}
+
+var now = time.Now
+
+// New takes a baseline of the disallowed and unknown dependencies in the
+// report
+func New(report *checker.Report) *Baseline {
+	return &Baseline{
+		CreatedAt: now().UTC().Truncate(time.Second),
+		Problems:  report.BaselineEntries(),
+	}
+}
+
+// Load reads a baseline written by Write
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline %s: %w", path, err)
+	}
+
+	var baseline Baseline
+	if err := yaml.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("baseline %s is not valid yaml: %w", path, err)
+	}
+
+	return &baseline, nil
+}
+
+// Write writes the baseline to a yaml file
+func (b *Baseline) Write(path string) error {
+	return atomicfile.WriteFile(path, 0644, func(w io.Writer) error {
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(b); err != nil {
+			return fmt.Errorf("failed to encode baseline as yaml: %w", err)
+		}
+		return encoder.Close()
+	})
+}
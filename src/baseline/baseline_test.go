@@ -0,0 +1,49 @@
+package baseline_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/baseline"
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAndLoad(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":    "MIT",
+		"disallowed-dep": "GPL-3.0-only",
+		"unknown-dep":    "ISC",
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "baseline.yaml")
+	require.NoError(t, baseline.New(report).Write(path))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `problems:
+  - dependency: disallowed-dep
+    license: GPL-3.0-only
+    verdict: disallowed
+  - dependency: unknown-dep
+    license: ISC
+    verdict: unknown
+`)
+
+	loaded, err := baseline.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []checker.BaselineEntry{
+		{Dependency: "disallowed-dep", License: "GPL-3.0-only", Verdict: checker.VerdictDisallowed},
+		{Dependency: "unknown-dep", License: "ISC", Verdict: checker.VerdictUnknown},
+	}, loaded.Problems)
+	assert.False(t, loaded.CreatedAt.IsZero())
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := baseline.Load(filepath.Join(t.TempDir(), "baseline.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -2,6 +2,7 @@ package boolexpr
 
 import (
 	"fmt"
+	"strconv"
 )
 
 type Operator int
@@ -36,3 +37,39 @@ func New(expression string) (*Node, error) {
 	}
 	return root, nil
 }
+
+// Variables returns the names of all variables in the expression, in the order
+// they appear. Boolean literals like `T` and `true` are not variables.
+//
+// Example:
+//
+//	tree, _ := boolexpr.New("A && (B || !A)")
+//	fmt.Println(tree.Variables()) // Output: [A B]
+func (n *Node) Variables() []string {
+	var variables []string
+	seen := make(map[string]bool)
+
+	var visit func(node *Node)
+	visit = func(node *Node) {
+		if node == nil {
+			return
+		}
+
+		if node.Operator == LITERAL {
+			if _, err := strconv.ParseBool(node.rawLiteralValue); err == nil {
+				return
+			}
+			if !seen[node.rawLiteralValue] {
+				seen[node.rawLiteralValue] = true
+				variables = append(variables, node.rawLiteralValue)
+			}
+			return
+		}
+
+		visit(node.Left)
+		visit(node.Right)
+	}
+	visit(n)
+
+	return variables
+}
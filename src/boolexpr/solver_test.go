@@ -99,3 +99,23 @@ func TestUnknownVariable(t *testing.T) {
 	assert.Contains(t, err.Error(), "unknown variable")
 	assert.Contains(t, err.Error(), "A")
 }
+
+func TestNodeVariables(t *testing.T) {
+	tests := map[string][]string{
+		"A":                 {"A"},
+		"T":                 nil,
+		"A && B":            {"A", "B"},
+		"A && (B || !A)":    {"A", "B"},
+		"!C && (D || T)":    {"C", "D"},
+		"MIT || Apache-2.0": {"MIT", "Apache-2.0"},
+	}
+
+	for expression, expected := range tests {
+		t.Run(expression, func(t *testing.T) {
+			tree, err := boolexpr.New(expression)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, tree.Variables())
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package checker
+
+// BaselineEntry is a problem that is tolerated because it was already there
+// when the baseline was taken
+type BaselineEntry struct {
+	Dependency string  `yaml:"dependency"`
+	License    string  `yaml:"license"`
+	Verdict    Verdict `yaml:"verdict"`
+}
+
+// BaselineStatus describes how much of the baseline is left
+type BaselineStatus struct {
+	// The problems in the report that are in the baseline, keyed by dependency
+	Debt map[string]Decision
+	// The problems in the baseline that are gone
+	Fixed []BaselineEntry
+}
+
+// ApplyBaseline marks the problems in the report that are in the baseline as
+// baseline debt. A problem is only in the baseline if the dependency has the
+// same license and verdict as when the baseline was taken.
+func (r *Report) ApplyBaseline(entries []BaselineEntry) {
+	status := &BaselineStatus{Debt: make(map[string]Decision)}
+	for _, entry := range entries {
+		decision, found := r.Decisions[entry.Dependency]
+		if found && decision.License == entry.License && decision.Verdict == entry.Verdict {
+			status.Debt[entry.Dependency] = decision
+		} else {
+			status.Fixed = append(status.Fixed, entry)
+		}
+	}
+	r.Baseline = status
+}
+
+// IsBaselineDebt returns true if the problem with the dependency is in the
+// baseline
+func (r *Report) IsBaselineDebt(dependency string) bool {
+	if r.Baseline == nil {
+		return false
+	}
+	_, found := r.Baseline.Debt[dependency]
+	return found
+}
+
+// WithoutBaselineDebt returns a copy of a license -> dependencies map, like
+// Disallowed, without the dependencies whose problems are in the baseline.
+// Licenses without other dependencies are left out.
+func (r *Report) WithoutBaselineDebt(licenses map[string][]string) map[string][]string {
+	filtered := make(map[string][]string)
+	for license, dependencies := range licenses {
+		for _, dependency := range dependencies {
+			if !r.IsBaselineDebt(dependency) {
+				filtered[license] = append(filtered[license], dependency)
+			}
+		}
+	}
+	return filtered
+}
+
+// BaselineEntries returns the problems in the report, to take a baseline of
+func (r *Report) BaselineEntries() []BaselineEntry {
+	var entries []BaselineEntry
+	for _, decision := range r.SortedDecisions() {
+		if decision.Verdict == VerdictAllowed {
+			continue
+		}
+		entries = append(entries, BaselineEntry{
+			Dependency: decision.Dependency,
+			License:    decision.License,
+			Verdict:    decision.Verdict,
+		})
+	}
+	return entries
+}
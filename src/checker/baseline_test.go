@@ -0,0 +1,59 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyBaseline(t *testing.T) {
+	lc := NewFromLists([]string{"MIT"}, []string{"GPL-3.0-only", "AGPL-3.0-only"})
+	baselineReport, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":  "MIT",
+		"old-gpl-dep":  "GPL-3.0-only",
+		"old-unknown":  "ISC",
+		"fixed-dep":    "GPL-3.0-only",
+		"relicensed":   "ISC",
+		"newly-denied": "AGPL-3.0-only",
+	})
+	require.NoError(t, err)
+	entries := baselineReport.BaselineEntries()
+	require.Len(t, entries, 5)
+
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":  "MIT",
+		"old-gpl-dep":  "GPL-3.0-only",
+		"old-unknown":  "ISC",
+		"relicensed":   "AGPL-3.0-only",
+		"newly-denied": "AGPL-3.0-only",
+		"new-gpl-dep":  "GPL-3.0-only",
+	})
+	require.NoError(t, err)
+	report.ApplyBaseline(entries)
+
+	assert.True(t, report.IsBaselineDebt("old-gpl-dep"))
+	assert.True(t, report.IsBaselineDebt("old-unknown"))
+	assert.True(t, report.IsBaselineDebt("newly-denied"))
+	// the license changed, so the problem is new
+	assert.False(t, report.IsBaselineDebt("relicensed"))
+	assert.False(t, report.IsBaselineDebt("new-gpl-dep"))
+
+	assert.Equal(t, []BaselineEntry{
+		{Dependency: "fixed-dep", License: "GPL-3.0-only", Verdict: VerdictDisallowed},
+		{Dependency: "relicensed", License: "ISC", Verdict: VerdictUnknown},
+	}, report.Baseline.Fixed)
+
+	assert.Equal(t, map[string][]string{
+		"GPL-3.0-only":  {"new-gpl-dep"},
+		"AGPL-3.0-only": {"relicensed"},
+	}, report.WithoutBaselineDebt(report.Disallowed))
+	assert.Empty(t, report.WithoutBaselineDebt(report.Unknown))
+}
+
+func TestWithoutBaselineDebt_NoBaseline(t *testing.T) {
+	report := &Report{}
+	report.RecordDisallowed("GPL-3.0-only", "some-dep")
+
+	assert.Equal(t, report.Disallowed, report.WithoutBaselineDebt(report.Disallowed))
+}
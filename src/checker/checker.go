@@ -3,10 +3,16 @@ package checker
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"sync"
 
+	"github.com/eriklarko/license-checker/src/atomicfile"
 	"github.com/eriklarko/license-checker/src/boolexpr"
+	"github.com/eriklarko/license-checker/src/obligations"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,8 +29,33 @@ func (ule *UnknownLicenseError) Error() string {
 //
 // To specify what happens when unknown licenses are encountered, you can
 // provide a callback using the `onUnknownLicense` constructor parameter
+//
+// A LicenseChecker is safe for concurrent use by multiple goroutines, except
+// for setting Obligations, which has to be done before it's shared.
 type LicenseChecker struct {
+	// guards the decisions, reasons and document
+	mu sync.RWMutex
+
 	context map[string]bool
+	// decisions that only apply to single dependencies, keyed by dependency.
+	// They take precedence over the decisions in `context`.
+	dependencyContexts map[string]map[string]bool
+	// why the decisions were made, if known
+	reasons map[decisionKey]string
+
+	// where the decisions in `context` were read from, if anywhere
+	source string
+	// the yaml document the decisions were read from, if any. Write updates
+	// it instead of replacing it, to keep the comments and order in the file.
+	document *yaml.Node
+
+	// the parsed license expressions, keyed by expression, so that every
+	// expression is only parsed once
+	expressions sync.Map
+
+	// Obligations is used to list the obligations of the allowed
+	// dependencies' licenses in reports. No obligations are listed if nil.
+	Obligations *obligations.Catalogue
 }
 
 func NewFromFile(path string) (*LicenseChecker, error) {
@@ -37,19 +68,29 @@ func NewFromFile(path string) (*LicenseChecker, error) {
 	}
 	defer file.Close()
 
-	licenseMap := make(map[string]bool)
-	err = yaml.NewDecoder(file).Decode(&licenseMap)
+	data, err := io.ReadAll(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode yaml: %w", err)
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
 	}
 
-	return NewFromMap(licenseMap), nil
+	p, err := parsePolicy(data)
+	if err != nil {
+		return nil, err
+	}
 
+	lc := NewFromMap(p.context)
+	lc.dependencyContexts = p.dependencyContexts
+	lc.reasons = p.reasons
+	lc.document = p.document
+	lc.source = path
+	return lc, nil
 }
 
 func NewFromMap(context map[string]bool) *LicenseChecker {
 	return &LicenseChecker{
-		context: context,
+		context:            context,
+		dependencyContexts: make(map[string]map[string]bool),
+		reasons:            make(map[decisionKey]string),
 	}
 }
 
@@ -75,15 +116,121 @@ func buildContext(approvedLicenses []string, disallowedLicenses []string) map[st
 
 // Update updates the license decision for a dependency
 func (lc *LicenseChecker) Update(license string, isAllowed bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
 	lc.context[license] = isAllowed
 }
 
+// UpdateForDependency makes a decision on a license that only applies to one
+// dependency, overriding the decision for all dependencies
+func (lc *LicenseChecker) UpdateForDependency(dependency string, license string, isAllowed bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	context, found := lc.dependencyContexts[dependency]
+	if !found {
+		context = make(map[string]bool)
+		lc.dependencyContexts[dependency] = context
+	}
+	context[license] = isAllowed
+}
+
+// Remove forgets the decision on a license, and why it was made. Returns false
+// if no decision had been made.
+func (lc *LicenseChecker) Remove(license string) bool {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	_, found := lc.context[license]
+	delete(lc.context, license)
+	delete(lc.reasons, decisionKey{license: license})
+	return found
+}
+
+// RemoveForDependency forgets a decision that only applies to one dependency,
+// and why it was made. Returns false if no such decision had been made.
+func (lc *LicenseChecker) RemoveForDependency(dependency string, license string) bool {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	context := lc.dependencyContexts[dependency]
+	_, found := context[license]
+	delete(context, license)
+	if len(context) == 0 {
+		delete(lc.dependencyContexts, dependency)
+	}
+	delete(lc.reasons, decisionKey{dependency: dependency, license: license})
+	return found
+}
+
+// SetReason records why the decision on a license was made. The reason is
+// written as a comment above the decision. An empty reason removes it.
+func (lc *LicenseChecker) SetReason(license string, reason string) {
+	lc.setReason(decisionKey{license: license}, reason)
+}
+
+// SetReasonForDependency records why a decision that only applies to one
+// dependency was made
+func (lc *LicenseChecker) SetReasonForDependency(dependency string, license string, reason string) {
+	lc.setReason(decisionKey{dependency: dependency, license: license}, reason)
+}
+
+func (lc *LicenseChecker) setReason(key decisionKey, reason string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if reason == "" {
+		delete(lc.reasons, key)
+	} else {
+		lc.reasons[key] = reason
+	}
+}
+
+// Reason returns why the decision on a license was made, if known
+func (lc *LicenseChecker) Reason(license string) string {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	return lc.reasons[decisionKey{license: license}]
+}
+
+// ReasonForDependency returns why a decision that only applies to one
+// dependency was made, if known
+func (lc *LicenseChecker) ReasonForDependency(dependency string, license string) string {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	return lc.reasons[decisionKey{dependency: dependency, license: license}]
+}
+
+// contextFor returns the decisions that apply to a dependency. The caller
+// must hold the read lock while using them.
+func (lc *LicenseChecker) contextFor(dependency string) map[string]bool {
+	overrides, found := lc.dependencyContexts[dependency]
+	if !found {
+		return lc.context
+	}
+
+	context := make(map[string]bool, len(lc.context)+len(overrides))
+	for license, allowed := range lc.context {
+		context[license] = allowed
+	}
+	for license, allowed := range overrides {
+		context[license] = allowed
+	}
+	return context
+}
+
 func (lc *LicenseChecker) IsLicenseAllowed(license string) (bool, error) {
-	node, err := boolexpr.New(license)
+	node, err := lc.parse(license)
 	if err != nil {
-		return false, fmt.Errorf("failed to parse license '%s': %w", license, err)
+		return false, err
 	}
 
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
 	var errUnknownVar *boolexpr.UnknownVariableError
 	solution, err := node.Solve(lc.context)
 	if errors.As(err, &errUnknownVar) {
@@ -95,42 +242,225 @@ func (lc *LicenseChecker) IsLicenseAllowed(license string) (bool, error) {
 	return solution, nil
 }
 
+// parsedExpression is a license expression parsed by boolexpr, or the error
+// parsing it failed with
+type parsedExpression struct {
+	node *boolexpr.Node
+	err  error
+}
+
+// parse parses a license expression, or returns the result of parsing it the
+// last time. The returned node must not be modified.
+func (lc *LicenseChecker) parse(license string) (*boolexpr.Node, error) {
+	if parsed, found := lc.expressions.Load(license); found {
+		return parsed.(parsedExpression).node, parsed.(parsedExpression).err
+	}
+
+	node, err := boolexpr.New(license)
+	if err != nil {
+		err = fmt.Errorf("failed to parse license '%s': %w", license, err)
+	}
+	lc.expressions.Store(license, parsedExpression{node: node, err: err})
+	return node, err
+}
+
+// ValidateCurrentLicenses checks the licenses of the dependencies, keyed by
+// dependency. The dependencies are checked in parallel, against the decisions
+// made when the validation started.
 func (lc *LicenseChecker) ValidateCurrentLicenses(currentLicenses map[string]string) (*Report, error) {
-	report := &Report{}
-	for dependency, license := range currentLicenses {
-		slog.Debug("Checking license", "license", license, "dependency", dependency)
+	dependencies := sortedKeys(currentLicenses)
+	decisions := make([]Decision, len(dependencies))
+	errs := make([]error, len(dependencies))
 
-		var errUnknownLicense *UnknownLicenseError
-		allowed, err := lc.IsLicenseAllowed(license)
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
 
-		if errors.As(err, &errUnknownLicense) {
-			report.RecordUnknownLicense(license, dependency)
-		} else if err != nil {
-			return nil, fmt.Errorf("failed to check if license is allowed or not: %w", err)
-		} else {
-			report.RecordDecision(license, dependency, allowed)
+	// the dependencies are handed out by index, so the decisions can be
+	// recorded in a deterministic order below
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(dependencies)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				dependency := dependencies[i]
+				slog.Debug("Checking license", "license", currentLicenses[dependency], "dependency", dependency)
+				decisions[i], errs[i] = lc.decide(dependency, currentLicenses[dependency])
+			}
+		}()
+	}
+	for i := range dependencies {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	report := &Report{}
+	for i, decision := range decisions {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to check if license is allowed or not: %w", errs[i])
 		}
+		report.Record(decision)
+		lc.recordObligations(report, decision)
 	}
 
 	return report, nil
 }
 
-func (lc *LicenseChecker) Write(path string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+// recordObligations records the obligations of an allowed dependency. When a
+// dependency is allowed by several alternative licenses, the obligations of
+// all of them are recorded, as it isn't known which one the project relies on.
+// The caller must hold the read lock.
+func (lc *LicenseChecker) recordObligations(report *Report, decision Decision) {
+	if lc.Obligations == nil {
+		return
 	}
-	defer file.Close()
 
-	yamlBytes, err := yaml.Marshal(lc.context)
+	for _, license := range lc.allowedLicenses(decision) {
+		obligations, found := lc.Obligations.Obligations(license)
+		if !found {
+			slog.Debug("License not in obligations catalogue", "license", license, "dependency", decision.Dependency)
+			continue
+		}
+		for _, obligation := range obligations {
+			report.RecordObligation(obligation, license, decision.Dependency)
+		}
+	}
+}
+
+// AllowedLicenses returns the licenses an allowed dependency can be used
+// under. When a dependency is allowed by one of several alternative licenses,
+// the alternatives that are denied are left out.
+func (lc *LicenseChecker) AllowedLicenses(decision Decision) []string {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	return lc.allowedLicenses(decision)
+}
+
+// allowedLicenses is AllowedLicenses for callers holding the read lock
+func (lc *LicenseChecker) allowedLicenses(decision Decision) []string {
+	if decision.Verdict != VerdictAllowed {
+		return nil
+	}
+
+	context := lc.contextFor(decision.Dependency)
+	var allowed []string
+	for _, license := range decision.DecidedBy {
+		if context[license] {
+			allowed = append(allowed, license)
+		}
+	}
+	return allowed
+}
+
+// decide checks a dependency's license and explains the verdict. The caller
+// must hold the read lock.
+func (lc *LicenseChecker) decide(dependency, license string) (Decision, error) {
+	node, err := lc.parse(license)
 	if err != nil {
-		return fmt.Errorf("failed to marshal yaml: %w", err)
+		return Decision{}, err
 	}
 
-	_, err = file.Write(yamlBytes)
+	decision := Decision{
+		Dependency: dependency,
+		License:    license,
+	}
+
+	context := lc.contextFor(dependency)
+	var undecided []string
+	// the decisions that only apply to this dependency, described as such
+	var decidedBy []string
+	for _, variable := range node.Variables() {
+		if _, ok := context[variable]; !ok {
+			undecided = append(undecided, variable)
+			continue
+		}
+
+		decision.DecidedBy = append(decision.DecidedBy, variable)
+		if _, ok := lc.dependencyContexts[dependency][variable]; ok {
+			decidedBy = append(decidedBy, fmt.Sprintf("%s (only for %s)", variable, dependency))
+		} else {
+			decidedBy = append(decidedBy, variable)
+		}
+	}
+
+	var errUnknownVar *boolexpr.UnknownVariableError
+	allowed, err := node.Solve(context)
+	if errors.As(err, &errUnknownVar) {
+		decision.Verdict = VerdictUnknown
+		decision.DecidedBy = undecided
+		decision.Reason = fmt.Sprintf("no decision has been made for %s", strings.Join(undecided, ", "))
+		return decision, nil
+	} else if err != nil {
+		return Decision{}, fmt.Errorf("failed to solve license '%s': %w", license, err)
+	}
+
+	decision.Source = lc.source
+	if allowed {
+		decision.Verdict = VerdictAllowed
+		decision.Reason = fmt.Sprintf("allowed by the decisions for %s", strings.Join(decidedBy, ", "))
+	} else {
+		// only the denied licenses are to blame, not the allowed ones the
+		// license is combined with
+		var denied []string
+		var deniedBy []string
+		for i, license := range decision.DecidedBy {
+			if !context[license] {
+				denied = append(denied, license)
+				deniedBy = append(deniedBy, decidedBy[i])
+			}
+		}
+		decision.DecidedBy = denied
+		decision.Verdict = VerdictDisallowed
+		decision.Reason = fmt.Sprintf("disallowed by the decisions for %s", strings.Join(deniedBy, ", "))
+	}
+	return decision, nil
+}
+
+// ValidateProjects validates the licenses of several projects at once. The
+// returned report has a section per project. The projects are recorded in
+// sorted order, so a dependency used by several projects gets the verdict of
+// the first of them in the top-level report.
+func (lc *LicenseChecker) ValidateProjects(projectLicenses map[string]map[string]string) (*Report, error) {
+	report := &Report{}
+	for _, project := range sortedKeys(projectLicenses) {
+		projectReport, err := lc.ValidateCurrentLicenses(projectLicenses[project])
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate licenses of project %s: %w", project, err)
+		}
+		report.RecordProject(project, projectReport)
+	}
+
+	return report, nil
+}
+
+// Write writes the decisions to a yaml file, with the reasons as comments
+// above them. If the decisions were read from a file, its comments, order and
+// indentation are kept, and new decisions are inserted in sorted position.
+// The file is replaced atomically, so readers never see a half-written file.
+func (lc *LicenseChecker) Write(path string) error {
+	// writing updates the document, so readers have to wait
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	p := &policy{
+		context:            lc.context,
+		dependencyContexts: lc.dependencyContexts,
+		reasons:            lc.reasons,
+		document:           lc.document,
+	}
+	yamlBytes, err := p.marshal()
 	if err != nil {
-		return fmt.Errorf("failed to write yaml to file: %w", err)
+		return err
 	}
+	lc.document = p.document
 
-	return nil
+	return atomicfile.WriteFile(path, 0644, func(w io.Writer) error {
+		if _, err := w.Write(yamlBytes); err != nil {
+			return fmt.Errorf("failed to write yaml to file: %w", err)
+		}
+		return nil
+	})
 }
@@ -1,9 +1,13 @@
 package checker
 
 import (
+	"fmt"
+	"os"
+	"sync"
 	"testing"
 
 	helpers_test "github.com/eriklarko/license-checker/src/helpers"
+	"github.com/eriklarko/license-checker/src/obligations"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -95,6 +99,227 @@ func TestValidateCurrentLicenses(t *testing.T) {
 	assertMapsEqual(t, expectedUnknown, report.Unknown)
 }
 
+func TestValidateCurrentLicenses_ManyDependencies(t *testing.T) {
+	lc := NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+
+	currentLicenses := make(map[string]string)
+	var expectedAllowed, expectedDisallowed []string
+	for i := 0; i < 1000; i++ {
+		dependency := fmt.Sprintf("dependency-%04d", i)
+		if i%10 == 0 {
+			currentLicenses[dependency] = "GPL-3.0-only || Apache-2.0 && !MIT"
+			expectedDisallowed = append(expectedDisallowed, dependency)
+		} else {
+			currentLicenses[dependency] = "MIT || GPL-3.0-only"
+			expectedAllowed = append(expectedAllowed, dependency)
+		}
+	}
+
+	report, err := lc.ValidateCurrentLicenses(currentLicenses)
+	require.NoError(t, err)
+
+	// the dependencies are recorded in order, even though they are checked in
+	// parallel
+	assert.Equal(t, map[string][]string{"MIT || GPL-3.0-only": expectedAllowed}, report.Allowed)
+	assert.Equal(t, map[string][]string{"GPL-3.0-only || Apache-2.0 && !MIT": expectedDisallowed}, report.Disallowed)
+	assert.Len(t, report.Decisions, 1000)
+}
+
+func TestParseCachesExpressions(t *testing.T) {
+	lc := NewFromMap(map[string]bool{})
+
+	first, err := lc.parse("MIT || Apache-2.0")
+	require.NoError(t, err)
+	second, err := lc.parse("MIT || Apache-2.0")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	other, err := lc.parse("MIT")
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+}
+
+func TestConcurrentUse(t *testing.T) {
+	lc := NewFromLists([]string{"MIT"}, []string{"GPL-3.0-only"})
+	currentLicenses := map[string]string{
+		"dep-1": "MIT",
+		"dep-2": "Apache-2.0 || ISC",
+		"dep-3": "GPL-3.0-only",
+	}
+	file := helpers_test.CreateTempFile(t, "licenses.yaml").Name()
+
+	// run with -race to detect unsynchronized access
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			lc.Update(fmt.Sprintf("License-%d", i), i%2 == 0)
+			lc.UpdateForDependency("dep-3", "GPL-3.0-only", true)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := lc.ValidateCurrentLicenses(currentLicenses)
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := lc.IsLicenseAllowed("MIT && !GPL-3.0-only")
+			assert.NoError(t, err)
+			lc.SetReason("MIT", "permissive")
+			lc.Reason("MIT")
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, lc.Write(file))
+		}()
+	}
+	wg.Wait()
+
+	report, err := lc.ValidateCurrentLicenses(currentLicenses)
+	require.NoError(t, err)
+	assert.Equal(t, VerdictAllowed, report.Decisions["dep-3"].Verdict)
+}
+
+func TestValidateCurrentLicenses_Decisions(t *testing.T) {
+	licensesFile := helpers_test.CreateTempFileWithContents(t, "MIT: true\nGPL-3.0: false\n")
+	lc, err := NewFromFile(licensesFile)
+	require.NoError(t, err)
+
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"some-dependency-1": "MIT || Apache-2.0",
+		"some-dependency-2": "MIT && GPL-3.0",
+		"some-dependency-3": "WTFPL || Apache-2.0",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Decision{
+		{
+			Dependency: "some-dependency-1",
+			License:    "MIT || Apache-2.0",
+			Verdict:    VerdictUnknown,
+			DecidedBy:  []string{"Apache-2.0"},
+			Reason:     "no decision has been made for Apache-2.0",
+		},
+		{
+			Dependency: "some-dependency-2",
+			License:    "MIT && GPL-3.0",
+			Verdict:    VerdictDisallowed,
+			DecidedBy:  []string{"GPL-3.0"},
+			Reason:     "disallowed by the decisions for GPL-3.0",
+			Source:     licensesFile,
+		},
+		{
+			Dependency: "some-dependency-3",
+			License:    "WTFPL || Apache-2.0",
+			Verdict:    VerdictUnknown,
+			DecidedBy:  []string{"WTFPL", "Apache-2.0"},
+			Reason:     "no decision has been made for WTFPL, Apache-2.0",
+		},
+	}, report.SortedDecisions())
+
+	lc.Update("Apache-2.0", true)
+	report, err = lc.ValidateCurrentLicenses(map[string]string{"some-dependency-1": "MIT || Apache-2.0"})
+	require.NoError(t, err)
+
+	assert.Equal(t, Decision{
+		Dependency: "some-dependency-1",
+		License:    "MIT || Apache-2.0",
+		Verdict:    VerdictAllowed,
+		DecidedBy:  []string{"MIT", "Apache-2.0"},
+		Reason:     "allowed by the decisions for MIT, Apache-2.0",
+		Source:     licensesFile,
+	}, report.Decisions["some-dependency-1"])
+}
+
+func TestValidateCurrentLicenses_Obligations(t *testing.T) {
+	catalogue, err := obligations.Parse([]byte(`
+conditions:
+  include-copyright: Include the copyright notice
+  include-notice: Propagate the NOTICE file
+  disclose-source: Make the source code available
+licenses:
+  MIT:
+    conditions: [include-copyright]
+  Apache-2.0:
+    conditions: [include-copyright, include-notice]
+  GPL-3.0:
+    conditions: [include-copyright, disclose-source]
+`))
+	require.NoError(t, err)
+
+	lc := NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0"})
+	lc.Obligations = catalogue
+
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"some-dependency-1": "MIT",
+		"some-dependency-2": "Apache-2.0 || GPL-3.0",
+		"some-dependency-3": "GPL-3.0",
+		"some-dependency-4": "WTFPL",
+	})
+	require.NoError(t, err)
+
+	// only the allowed licenses of allowed dependencies trigger obligations
+	assert.Equal(t, []TriggeredObligation{
+		{
+			ID:           "include-copyright",
+			Description:  "Include the copyright notice",
+			Licenses:     []string{"Apache-2.0", "MIT"},
+			Dependencies: []string{"some-dependency-1", "some-dependency-2"},
+		},
+		{
+			ID:           "include-notice",
+			Description:  "Propagate the NOTICE file",
+			Licenses:     []string{"Apache-2.0"},
+			Dependencies: []string{"some-dependency-2"},
+		},
+	}, report.SortedObligations())
+
+	t.Run("without a catalogue", func(t *testing.T) {
+		lc.Obligations = nil
+		report, err := lc.ValidateCurrentLicenses(map[string]string{"some-dependency-1": "MIT"})
+		require.NoError(t, err)
+
+		assert.Empty(t, report.SortedObligations())
+	})
+}
+
+func TestValidateProjects(t *testing.T) {
+	lc := NewFromLists([]string{"MIT"}, []string{"GPL-3.0"})
+
+	report, err := lc.ValidateProjects(map[string]map[string]string{
+		"frontend": {"some-dependency-1": "MIT"},
+		"backend":  {"some-dependency-2": "GPL-3.0", "some-dependency-3": "WTFPL"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"backend", "frontend"}, report.ProjectNames())
+	assertMapsEqual(t, map[string][]string{"MIT": {"some-dependency-1"}}, report.Projects["frontend"].Allowed)
+	assertMapsEqual(t, map[string][]string{"GPL-3.0": {"some-dependency-2"}}, report.Projects["backend"].Disallowed)
+	assertMapsEqual(t, map[string][]string{"WTFPL": {"some-dependency-3"}}, report.Projects["backend"].Unknown)
+
+	assertMapsEqual(t, map[string][]string{"GPL-3.0": {"some-dependency-2"}}, report.Disallowed)
+}
+
+func TestValidateProjects_SharedDependency(t *testing.T) {
+	lc := NewFromLists([]string{"MIT"}, []string{"GPL-3.0"})
+
+	// the first project in sorted order wins, however the map is iterated
+	for range 10 {
+		report, err := lc.ValidateProjects(map[string]map[string]string{
+			"frontend": {"shared": "GPL-3.0"},
+			"backend":  {"shared": "MIT"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, VerdictAllowed, report.Decisions["shared"].Verdict)
+		assert.Equal(t, "MIT", report.Decisions["shared"].License)
+		assertMapsEqual(t, map[string][]string{"MIT": {"shared"}}, report.Allowed)
+		assert.Empty(t, report.Disallowed)
+		assert.Equal(t, VerdictDisallowed, report.Projects["frontend"].Decisions["shared"].Verdict)
+	}
+}
+
 func TestNewFromFile(t *testing.T) {
 	t.Run("valid content", func(t *testing.T) {
 		content := `MIT,true
@@ -151,3 +376,283 @@ func assertMapsEqual(t *testing.T, expected, actual map[string][]string) {
 		assert.ElementsMatch(t, expectedValues, actualValues)
 	}
 }
+
+func TestDependencyDecisions(t *testing.T) {
+	lc := NewFromLists([]string{"MIT"}, []string{"GPL-3.0-only"})
+	lc.UpdateForDependency("special", "GPL-3.0-only", true)
+
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"special": "GPL-3.0-only",
+		"other":   "GPL-3.0-only",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, VerdictAllowed, report.Decisions["special"].Verdict)
+	assert.Equal(t, "allowed by the decisions for GPL-3.0-only (only for special)", report.Decisions["special"].Reason)
+	assert.Equal(t, VerdictDisallowed, report.Decisions["other"].Verdict)
+
+	assert.True(t, lc.RemoveForDependency("special", "GPL-3.0-only"))
+	assert.False(t, lc.RemoveForDependency("special", "GPL-3.0-only"))
+	report, err = lc.ValidateCurrentLicenses(map[string]string{"special": "GPL-3.0-only"})
+	require.NoError(t, err)
+	assert.Equal(t, VerdictDisallowed, report.Decisions["special"].Verdict)
+}
+
+func TestRemove(t *testing.T) {
+	lc := NewFromLists([]string{"MIT"}, nil)
+	lc.SetReason("MIT", "permissive")
+
+	assert.True(t, lc.Remove("MIT"))
+	assert.False(t, lc.Remove("MIT"))
+	assert.Empty(t, lc.Reason("MIT"))
+
+	var errUnknownLicense *UnknownLicenseError
+	_, err := lc.IsLicenseAllowed("MIT")
+	assert.ErrorAs(t, err, &errUnknownLicense)
+}
+
+func TestWriteAndReadPolicy(t *testing.T) {
+	lc := NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+	lc.SetReason("GPL-3.0-only", "we distribute binaries\nsee the legal wiki")
+	lc.UpdateForDependency("some-tool", "GPL-3.0-only", true)
+	lc.SetReasonForDependency("some-tool", "GPL-3.0-only", "only used in CI")
+
+	file := helpers_test.CreateTempFile(t, "licenses.yaml").Name()
+	require.NoError(t, lc.Write(file))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, `Apache-2.0: true
+# we distribute binaries
+# see the legal wiki
+GPL-3.0-only: false
+MIT: true
+dependencies:
+    some-tool:
+        # only used in CI
+        GPL-3.0-only: true
+`, string(content))
+
+	read, err := NewFromFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, lc.context, read.context)
+	assert.Equal(t, lc.dependencyContexts, read.dependencyContexts)
+	assert.Equal(t, "we distribute binaries\nsee the legal wiki", read.Reason("GPL-3.0-only"))
+	assert.Equal(t, "only used in CI", read.ReasonForDependency("some-tool", "GPL-3.0-only"))
+}
+
+func TestWriteKeepsCommentsAndOrder(t *testing.T) {
+	file := helpers_test.CreateTempFile(t, "licenses.yaml").Name()
+	require.NoError(t, os.WriteFile(file, []byte(`# Reviewed by legal, ask before changing.
+
+# the most common license
+MIT: true
+ISC: yes # same as MIT
+GPL-3.0-only: false
+Zlib: true
+dependencies:
+  some-tool:
+    # only used in CI
+    GPL-3.0-only: true
+  removed-tool:
+    GPL-2.0-only: true
+`), 0644))
+
+	lc, err := NewFromFile(file)
+	require.NoError(t, err)
+	lc.Update("Apache-2.0", true)
+	lc.SetReason("Apache-2.0", "permissive")
+	lc.Update("BSD-3-Clause", false)
+	lc.Update("GPL-3.0-only", true)
+	lc.Remove("Zlib")
+	lc.RemoveForDependency("removed-tool", "GPL-2.0-only")
+	lc.UpdateForDependency("another-tool", "AGPL-3.0-only", true)
+	require.NoError(t, lc.Write(file))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, `# Reviewed by legal, ask before changing.
+
+# permissive
+Apache-2.0: true
+BSD-3-Clause: false
+# the most common license
+MIT: true
+ISC: yes # same as MIT
+GPL-3.0-only: true
+dependencies:
+  another-tool:
+    AGPL-3.0-only: true
+  some-tool:
+    # only used in CI
+    GPL-3.0-only: true
+`, string(content))
+}
+
+func TestWriteKeepsBlankLines(t *testing.T) {
+	file := helpers_test.CreateTempFile(t, "licenses.yaml").Name()
+	require.NoError(t, os.WriteFile(file, []byte(`# Reviewed by legal
+
+# permissive licenses
+MIT: true
+ISC: true
+
+# copyleft
+GPL-3.0-only: false
+
+AGPL-3.0-only: false
+
+dependencies:
+   some-tool:
+      # only used in CI
+      GPL-3.0-only: true
+
+   other-tool:
+      AGPL-3.0-only: true
+`), 0644))
+
+	lc, err := NewFromFile(file)
+	require.NoError(t, err)
+	lc.Update("Zlib", true)
+	lc.SetReason("GPL-3.0-only", "copyleft, ask legal")
+	lc.Remove("AGPL-3.0-only")
+	lc.UpdateForDependency("other-tool", "MPL-2.0", true)
+	require.NoError(t, lc.Write(file))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, `# Reviewed by legal
+
+# permissive licenses
+MIT: true
+ISC: true
+
+# copyleft, ask legal
+GPL-3.0-only: false
+Zlib: true
+
+dependencies:
+   some-tool:
+      # only used in CI
+      GPL-3.0-only: true
+
+   other-tool:
+      AGPL-3.0-only: true
+      MPL-2.0: true
+`, string(content))
+
+	// reading and writing the file again doesn't change it
+	lc, err = NewFromFile(file)
+	require.NoError(t, err)
+	require.NoError(t, lc.Write(file))
+	rewritten, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, string(content), string(rewritten))
+}
+
+func TestHeaderComment(t *testing.T) {
+	header := `---
+# Licenses policy for ACME
+# ask legal before changing
+
+MIT: true
+# copyleft
+
+# we distribute binaries
+GPL-3.0-only: false
+`
+	newFile := func(t *testing.T) (string, *LicenseChecker) {
+		file := helpers_test.CreateTempFile(t, "licenses.yaml").Name()
+		require.NoError(t, os.WriteFile(file, []byte(header), 0644))
+		lc, err := NewFromFile(file)
+		require.NoError(t, err)
+		return file, lc
+	}
+	read := func(t *testing.T, file string) string {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	t.Run("is not read as a reason", func(t *testing.T) {
+		_, lc := newFile(t)
+		assert.Equal(t, "", lc.Reason("MIT"))
+		assert.Equal(t, "we distribute binaries", lc.Reason("GPL-3.0-only"))
+	})
+
+	t.Run("new decisions are written below it", func(t *testing.T) {
+		file, lc := newFile(t)
+		lc.Update("Apache-2.0", true)
+		require.NoError(t, lc.Write(file))
+
+		assert.Equal(t, `# Licenses policy for ACME
+# ask legal before changing
+
+Apache-2.0: true
+MIT: true
+# copyleft
+
+# we distribute binaries
+GPL-3.0-only: false
+`, read(t, file))
+	})
+
+	t.Run("is kept when setting reasons", func(t *testing.T) {
+		file, lc := newFile(t)
+		lc.SetReason("MIT", "permissive")
+		lc.SetReason("GPL-3.0-only", "")
+		require.NoError(t, lc.Write(file))
+
+		assert.Equal(t, `# Licenses policy for ACME
+# ask legal before changing
+
+# permissive
+MIT: true
+# copyleft
+
+GPL-3.0-only: false
+`, read(t, file))
+	})
+
+	t.Run("of a file without decisions", func(t *testing.T) {
+		file := helpers_test.CreateTempFile(t, "licenses.yaml").Name()
+		require.NoError(t, os.WriteFile(file, []byte("# Licenses policy for ACME\n"), 0644))
+		lc, err := NewFromFile(file)
+		require.NoError(t, err)
+		lc.Update("MIT", true)
+		require.NoError(t, lc.Write(file))
+
+		assert.Equal(t, "# Licenses policy for ACME\n\nMIT: true\n", read(t, file))
+	})
+}
+
+func TestWriteWithoutChangesKeepsFile(t *testing.T) {
+	content := `# Reviewed by legal
+
+Zlib: true
+# the most common license
+MIT: true
+dependencies:
+  some-tool:
+    GPL-3.0-only: true
+`
+	file := helpers_test.CreateTempFile(t, "licenses.yaml").Name()
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+
+	lc, err := NewFromFile(file)
+	require.NoError(t, err)
+	require.NoError(t, lc.Write(file))
+
+	written, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(written))
+}
+
+func TestIsSingleLicense(t *testing.T) {
+	assert.True(t, IsSingleLicense("MIT"))
+	assert.True(t, IsSingleLicense("GPL-2.0+"))
+	assert.False(t, IsSingleLicense(""))
+	assert.False(t, IsSingleLicense("MIT || Apache-2.0"))
+	assert.False(t, IsSingleLicense("!MIT"))
+	assert.False(t, IsSingleLicense("Apache 2.0"))
+}
@@ -0,0 +1,450 @@
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DependenciesKey is the key of the section of the licenses file with the
+// decisions that only apply to single dependencies, like
+//
+//	MIT: true
+//	dependencies:
+//	  some-dependency:
+//	    GPL-3.0-only: true
+const DependenciesKey = "dependencies"
+
+// IsSingleLicense returns false if the license is an expression combining
+// several licenses, like `MIT || Apache-2.0`. Decisions are made on single
+// licenses.
+func IsSingleLicense(license string) bool {
+	return license != "" && !strings.ContainsAny(license, " \t()!&|")
+}
+
+// decisionKey identifies a decision. The dependency is empty for decisions
+// that apply to all dependencies.
+type decisionKey struct {
+	dependency string
+	license    string
+}
+
+// policy is the content of the licenses file
+type policy struct {
+	context            map[string]bool
+	dependencyContexts map[string]map[string]bool
+	// the comments above the decisions
+	reasons map[decisionKey]string
+
+	// the yaml document the decisions were read from. Kept to write the
+	// decisions back without losing the comments and order in the file.
+	document *yaml.Node
+}
+
+func parsePolicy(data []byte) (*policy, error) {
+	p := &policy{
+		context:            make(map[string]bool),
+		dependencyContexts: make(map[string]map[string]bool),
+		reasons:            make(map[decisionKey]string),
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to decode yaml: %w", err)
+	}
+	p.document = &document
+	if len(document.Content) == 0 {
+		// empty file, or a file with only comments, which are kept as the
+		// header of the file
+		if header := strings.TrimSpace(string(data)); header != "" {
+			p.document = &yaml.Node{
+				Kind:        yaml.DocumentNode,
+				HeadComment: header,
+				Content:     []*yaml.Node{{Kind: yaml.MappingNode}},
+			}
+		}
+		return p, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to decode yaml: line %d: expected a map of licenses to true or false", root.Line)
+	}
+	markBlankLines(root, strings.Split(string(data), "\n"), true)
+	moveHeader(&document, root)
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value == DependenciesKey && value.Kind == yaml.MappingNode {
+			if err := p.parseDependencies(value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		allowed, err := parseDecision(key, value)
+		if err != nil {
+			return nil, err
+		}
+		p.context[key.Value] = allowed
+
+		if reason := parseReason(key.HeadComment); reason != "" {
+			p.reasons[decisionKey{license: key.Value}] = reason
+		}
+	}
+
+	return p, nil
+}
+
+func (p *policy) parseDependencies(node *yaml.Node) error {
+	for i := 0; i < len(node.Content); i += 2 {
+		dependency, decisions := node.Content[i], node.Content[i+1]
+		if decisions.Kind != yaml.MappingNode {
+			return fmt.Errorf("failed to decode yaml: line %d: expected the decisions for %s to be a map of licenses to true or false", decisions.Line, dependency.Value)
+		}
+
+		context := make(map[string]bool)
+		for j := 0; j < len(decisions.Content); j += 2 {
+			key, value := decisions.Content[j], decisions.Content[j+1]
+			allowed, err := parseDecision(key, value)
+			if err != nil {
+				return err
+			}
+			context[key.Value] = allowed
+
+			if reason := parseReason(key.HeadComment); reason != "" {
+				p.reasons[decisionKey{dependency: dependency.Value, license: key.Value}] = reason
+			}
+		}
+		p.dependencyContexts[dependency.Value] = context
+	}
+	return nil
+}
+
+func parseDecision(key, value *yaml.Node) (bool, error) {
+	var allowed bool
+	if err := value.Decode(&allowed); err != nil {
+		return false, fmt.Errorf("failed to decode yaml: line %d: expected the decision for %s to be true or false, but got '%s'", value.Line, key.Value, value.Value)
+	}
+	return allowed, nil
+}
+
+// parseComment removes the comment markers from a yaml comment
+func parseComment(comment string) string {
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// parseReason returns the reason for a decision, which is the paragraph of
+// the comment directly above the decision
+func parseReason(comment string) string {
+	_, reason := splitComment(comment)
+	return parseComment(reason)
+}
+
+// splitComment splits the comment above a key into the part that is
+// separated from the key by a blank line, like a header or a comment on a
+// group of decisions, and the paragraph directly above the key. The first
+// part keeps the newline ending its last blank line, so that joining the two
+// parts gives back the comment.
+func splitComment(comment string) (string, string) {
+	if comment == "" {
+		return "", ""
+	}
+	lines := strings.Split(comment, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			return strings.Join(lines[:i+1], "\n") + "\n", strings.Join(lines[i+1:], "\n")
+		}
+	}
+	return "", comment
+}
+
+// moveHeader moves the comment at the top of the file from the first key to
+// the document when yaml.v3 didn't, like when the file starts with `---`, so
+// that it isn't read as the reason for the first decision and new decisions
+// are inserted below it
+func moveHeader(document, root *yaml.Node) {
+	if len(root.Content) == 0 {
+		return
+	}
+	first := root.Content[0]
+	header, reason := splitComment(first.HeadComment)
+	if header = strings.Trim(header, "\n"); header == "" {
+		return
+	}
+
+	if document.HeadComment != "" {
+		header = document.HeadComment + "\n\n" + header
+	}
+	document.HeadComment = header
+	first.HeadComment = reason
+}
+
+// toComment turns a reason into a yaml comment
+func toComment(reason string) string {
+	if reason == "" {
+		return ""
+	}
+
+	lines := strings.Split(reason, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("# "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// marshal writes the decisions into the document they were read from, so
+// that comments, the order of the decisions and the indentation are kept. New
+// decisions are inserted in sorted position, and the reasons are written as
+// comments above the decisions.
+func (p *policy) marshal() ([]byte, error) {
+	if p.document == nil || len(p.document.Content) == 0 {
+		p.document = &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode}},
+		}
+	}
+	root := p.document.Content[0]
+
+	isDependencies := func(key *yaml.Node) bool {
+		return key.Value == DependenciesKey
+	}
+	p.updateDecisions(root, "", p.context, isDependencies)
+	p.updateDependencies(root)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indentation(root))
+	if err := encoder.Encode(p.document); err != nil {
+		return nil, fmt.Errorf("failed to marshal yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal yaml: %w", err)
+	}
+
+	// the blank lines above nested keys are indented like the keys
+	lines := strings.Split(buf.String(), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// updateDecisions makes the decisions in the mapping node match the context.
+// Keys the skip function returns true for are left as they are.
+func (p *policy) updateDecisions(node *yaml.Node, dependency string, context map[string]bool, skip func(key *yaml.Node) bool) {
+	written := make(map[string]bool)
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if skip != nil && skip(key) {
+			content = append(content, key, value)
+			continue
+		}
+
+		allowed, found := context[key.Value]
+		if !found || written[key.Value] {
+			// removed, or the second decision on the same license
+			continue
+		}
+		written[key.Value] = true
+
+		setDecision(value, allowed)
+		p.setReason(key, decisionKey{dependency: dependency, license: key.Value})
+		content = append(content, key, value)
+	}
+	node.Content = content
+
+	for _, license := range sortedKeys(context) {
+		if written[license] {
+			continue
+		}
+
+		key := stringNode(license)
+		p.setReason(key, decisionKey{dependency: dependency, license: license})
+		value := &yaml.Node{}
+		setDecision(value, context[license])
+		insertSorted(node, key, value, skip)
+	}
+}
+
+// updateDependencies makes the dependencies section of the root node match
+// the decisions that only apply to single dependencies
+func (p *policy) updateDependencies(root *yaml.Node) {
+	index := -1
+	for i := 0; i < len(root.Content); i += 2 {
+		if root.Content[i].Value == DependenciesKey {
+			index = i
+			break
+		}
+	}
+
+	if len(p.dependencyContexts) == 0 {
+		if index >= 0 {
+			root.Content = append(root.Content[:index], root.Content[index+2:]...)
+		}
+		return
+	}
+	if index < 0 {
+		root.Content = append(root.Content, stringNode(DependenciesKey), &yaml.Node{Kind: yaml.MappingNode})
+		index = len(root.Content) - 2
+	}
+
+	dependencies := root.Content[index+1]
+	written := make(map[string]bool)
+	content := make([]*yaml.Node, 0, len(dependencies.Content))
+	for i := 0; i < len(dependencies.Content); i += 2 {
+		key, value := dependencies.Content[i], dependencies.Content[i+1]
+		context, found := p.dependencyContexts[key.Value]
+		if !found || written[key.Value] {
+			continue
+		}
+		written[key.Value] = true
+
+		p.updateDecisions(value, key.Value, context, nil)
+		content = append(content, key, value)
+	}
+	dependencies.Content = content
+
+	for _, dependency := range sortedKeys(p.dependencyContexts) {
+		if written[dependency] {
+			continue
+		}
+
+		decisions := &yaml.Node{Kind: yaml.MappingNode}
+		p.updateDecisions(decisions, dependency, p.dependencyContexts[dependency], nil)
+		insertSorted(dependencies, stringNode(dependency), decisions, nil)
+	}
+}
+
+// setReason writes the reason for a decision as the comment paragraph above
+// it, unless it already says the same thing. The comments separated from the
+// decision by a blank line are kept.
+func (p *policy) setReason(key *yaml.Node, decision decisionKey) {
+	reason := p.reasons[decision]
+	kept, current := splitComment(key.HeadComment)
+	if parseComment(current) == reason {
+		return
+	}
+
+	if reason == "" && kept != "\n" {
+		// the blank line separated the kept comment from the removed reason
+		kept = strings.TrimSuffix(kept, "\n")
+	}
+	key.HeadComment = kept + toComment(reason)
+}
+
+// markBlankLines makes the encoder keep the blank lines above the keys of the
+// mapping node and its nested mappings, which yaml.v3 otherwise drops. A head
+// comment starting with a newline is written with a blank line above it. The
+// first key of the document, and keys after a foot comment, are skipped, as
+// the encoder keeps the blank line after those comments itself.
+func markBlankLines(node *yaml.Node, lines []string, isRoot bool) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind == yaml.MappingNode {
+			markBlankLines(value, lines, false)
+		}
+		if isRoot && i == 0 {
+			continue
+		}
+		if i > 0 && (node.Content[i-2].FootComment != "" || node.Content[i-1].FootComment != "") {
+			// the encoder writes a blank line after the comment below the
+			// previous key
+			continue
+		}
+
+		// the 1-based line above the key and its comment
+		above := key.Line - 1
+		if key.HeadComment != "" {
+			above -= strings.Count(key.HeadComment, "\n") + 1
+		}
+		if above >= 1 && above <= len(lines) && strings.TrimSpace(lines[above-1]) == "" {
+			key.HeadComment = "\n" + key.HeadComment
+		}
+	}
+}
+
+// setDecision sets the value of a decision, keeping how it is written if the
+// decision didn't change
+func setDecision(value *yaml.Node, allowed bool) {
+	var current bool
+	if value.Kind == yaml.ScalarNode && value.Decode(&current) == nil && current == allowed {
+		return
+	}
+
+	value.Kind = yaml.ScalarNode
+	value.Tag = "!!bool"
+	value.Value = strconv.FormatBool(allowed)
+	value.Style = 0
+	value.Content = nil
+}
+
+// insertSorted inserts a key and its value before the first key that sorts
+// after it. Keys the skip function returns true for are ignored, so new
+// decisions are never added after the dependencies section.
+func insertSorted(node *yaml.Node, key, value *yaml.Node, skip func(key *yaml.Node) bool) {
+	index := 0
+	for i := 0; i < len(node.Content); i += 2 {
+		if skip != nil && skip(node.Content[i]) {
+			continue
+		}
+		if node.Content[i].Value > key.Value {
+			index = i
+			break
+		}
+		index = i + 2
+	}
+
+	node.Content = slices.Insert(node.Content, index, key, value)
+}
+
+// indentation returns how many spaces the nested maps of the document are
+// indented with, defaulting to four
+func indentation(root *yaml.Node) int {
+	var nested func(node *yaml.Node) int
+	nested = func(node *yaml.Node) int {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind != yaml.MappingNode || len(value.Content) == 0 || value.Style&yaml.FlowStyle != 0 {
+				continue
+			}
+			if indent := value.Content[0].Column - key.Column; indent > 0 {
+				return indent
+			}
+			if indent := nested(value); indent > 0 {
+				return indent
+			}
+		}
+		return 0
+	}
+
+	if indent := nested(root); indent > 0 {
+		return indent
+	}
+	return 4
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
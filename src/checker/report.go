@@ -1,10 +1,169 @@
 package checker
 
+import (
+	"sort"
+
+	"github.com/eriklarko/license-checker/src/obligations"
+	"github.com/samber/lo"
+)
+
+// Verdict is the outcome of checking a license
+type Verdict string
+
+const (
+	VerdictAllowed    Verdict = "allowed"
+	VerdictDisallowed Verdict = "disallowed"
+	VerdictUnknown    Verdict = "unknown"
+)
+
+// Decision describes why a dependency's license was allowed, disallowed or
+// unknown
+type Decision struct {
+	Dependency string
+	// The version of the dependency, if the collector knows it
+	Version string
+	License string
+	Verdict Verdict
+	// The licenses in the license expression the verdict is based on. For
+	// disallowed verdicts, these are the denied licenses, and for unknown
+	// verdicts the licenses no decision has been made for.
+	DecidedBy []string
+	// Human readable explanation of the verdict
+	Reason string
+	// Where the decisions the verdict is based on are stored, typically the
+	// path to the licenses file. Empty for unknown verdicts.
+	Source string
+}
+
+// TriggeredObligation is an obligation that comes with using the allowed
+// dependencies, like including their copyright notices
+type TriggeredObligation struct {
+	ID          string
+	Description string
+	// The licenses with the obligation
+	Licenses []string
+	// The dependencies using those licenses
+	Dependencies []string
+}
+
+// Relicensing is a dependency whose license expression changed since the
+// last time it was checked, typically in an upgrade
+type Relicensing struct {
+	Dependency      string
+	PreviousVersion string
+	PreviousLicense string
+	Version         string
+	License         string
+}
+
 type Report struct {
 	Allowed    map[string][]string
 	Disallowed map[string][]string
 
 	Unknown map[string][]string
+
+	// Decisions holds the verdict for each dependency, keyed by dependency
+	Decisions map[string]Decision
+
+	// Obligations holds the obligations of the allowed dependencies' licenses,
+	// keyed by obligation ID. Only set if the checker has an obligations
+	// catalogue.
+	Obligations map[string]*TriggeredObligation
+
+	// ChangedLicenseTexts holds the dependencies whose license text changed
+	// although their declared license didn't, and their declared license.
+	// These need to be reviewed again.
+	ChangedLicenseTexts map[string]string
+
+	// Relicensed holds the dependencies whose license expression changed
+	// since the last time they were checked, keyed by dependency
+	Relicensed map[string]Relicensing
+
+	// Baseline describes the problems that are tolerated because they are in
+	// the baseline. Nil if no baseline is used.
+	Baseline *BaselineStatus
+
+	// Projects holds a separate report for each project when several projects
+	// are checked in one run, keyed by the path to the project. The licenses
+	// of all projects are also recorded in the top-level maps.
+	Projects map[string]*Report
+}
+
+// Record records the verdict for a dependency
+func (r *Report) Record(decision Decision) {
+	if r.Decisions == nil {
+		r.Decisions = make(map[string]Decision)
+	}
+	r.Decisions[decision.Dependency] = decision
+
+	switch decision.Verdict {
+	case VerdictAllowed:
+		r.RecordAllowed(decision.License, decision.Dependency)
+	case VerdictDisallowed:
+		r.RecordDisallowed(decision.License, decision.Dependency)
+	default:
+		r.RecordUnknownLicense(decision.License, decision.Dependency)
+	}
+}
+
+// RecordObligation records that a dependency comes with an obligation
+// because of one of its licenses
+func (r *Report) RecordObligation(obligation obligations.Obligation, license string, dependency string) {
+	if r.Obligations == nil {
+		r.Obligations = make(map[string]*TriggeredObligation)
+	}
+
+	triggered, found := r.Obligations[obligation.ID]
+	if !found {
+		triggered = &TriggeredObligation{ID: obligation.ID, Description: obligation.Description}
+		r.Obligations[obligation.ID] = triggered
+	}
+	if !lo.Contains(triggered.Licenses, license) {
+		triggered.Licenses = append(triggered.Licenses, license)
+	}
+	if !lo.Contains(triggered.Dependencies, dependency) {
+		triggered.Dependencies = append(triggered.Dependencies, dependency)
+	}
+}
+
+// SortedObligations returns all triggered obligations sorted by ID, with
+// their licenses and dependencies sorted too
+func (r *Report) SortedObligations() []TriggeredObligation {
+	triggered := make([]TriggeredObligation, 0, len(r.Obligations))
+	for _, obligation := range r.Obligations {
+		o := *obligation
+		o.Licenses = append([]string(nil), o.Licenses...)
+		o.Dependencies = append([]string(nil), o.Dependencies...)
+		sort.Strings(o.Licenses)
+		sort.Strings(o.Dependencies)
+		triggered = append(triggered, o)
+	}
+	sort.Slice(triggered, func(i, j int) bool {
+		return triggered[i].ID < triggered[j].ID
+	})
+	return triggered
+}
+
+// SortedDecisions returns the verdicts of all dependencies, sorted by
+// dependency
+func (r *Report) SortedDecisions() []Decision {
+	decisions := lo.Values(r.Decisions)
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].Dependency < decisions[j].Dependency
+	})
+	return decisions
+}
+
+// RecordVersions sets the versions of the dependencies in this report and its
+// projects
+func (r *Report) RecordVersions(versions map[string]string) {
+	for dependency, decision := range r.Decisions {
+		decision.Version = versions[dependency]
+		r.Decisions[dependency] = decision
+	}
+	for _, project := range r.Projects {
+		project.RecordVersions(versions)
+	}
 }
 
 func (r *Report) RecordDecision(license string, dependency string, allowed bool) {
@@ -45,3 +204,116 @@ func (r *Report) RecordUnknownLicense(license string, dependency string) {
 func (r *Report) HasUnknownLicenses() bool {
 	return len(r.Unknown) > 0
 }
+
+// RecordProject adds the report of a single project to this report. A
+// dependency used by several projects is recorded with the verdict of the
+// first project it was recorded for, so projects have to be recorded in a
+// deterministic order.
+func (r *Report) RecordProject(project string, report *Report) {
+	if r.Projects == nil {
+		r.Projects = make(map[string]*Report)
+	}
+	r.Projects[project] = report
+
+	// the dependencies recorded by earlier projects
+	recorded := func(dependency string) bool {
+		_, found := r.Decisions[dependency]
+		return found
+	}
+	r.Allowed = mergeLicenseMaps(r.Allowed, report.Allowed, recorded)
+	r.Disallowed = mergeLicenseMaps(r.Disallowed, report.Disallowed, recorded)
+	r.Unknown = mergeLicenseMaps(r.Unknown, report.Unknown, recorded)
+
+	for id, obligation := range report.Obligations {
+		dependencies := lo.Reject(obligation.Dependencies, func(dependency string, _ int) bool {
+			return recorded(dependency)
+		})
+		if len(dependencies) == 0 {
+			continue
+		}
+
+		if r.Obligations == nil {
+			r.Obligations = make(map[string]*TriggeredObligation)
+		}
+		merged, found := r.Obligations[id]
+		if !found {
+			merged = &TriggeredObligation{ID: id, Description: obligation.Description}
+			r.Obligations[id] = merged
+		}
+		merged.Licenses = lo.Uniq(append(merged.Licenses, obligation.Licenses...))
+		merged.Dependencies = lo.Uniq(append(merged.Dependencies, dependencies...))
+	}
+
+	for _, decision := range report.Decisions {
+		if recorded(decision.Dependency) {
+			continue
+		}
+		if r.Decisions == nil {
+			r.Decisions = make(map[string]Decision)
+		}
+		r.Decisions[decision.Dependency] = decision
+	}
+}
+
+// RecordChangedLicenseText records that the license text of a dependency
+// changed without its declared license changing
+func (r *Report) RecordChangedLicenseText(dependency string, license string) {
+	if r.ChangedLicenseTexts == nil {
+		r.ChangedLicenseTexts = make(map[string]string)
+	}
+	r.ChangedLicenseTexts[dependency] = license
+}
+
+func (r *Report) HasChangedLicenseTexts() bool {
+	return len(r.ChangedLicenseTexts) > 0
+}
+
+// RecordRelicensing records that the license expression of a dependency
+// changed since the last time it was checked
+func (r *Report) RecordRelicensing(relicensing Relicensing) {
+	if r.Relicensed == nil {
+		r.Relicensed = make(map[string]Relicensing)
+	}
+	r.Relicensed[relicensing.Dependency] = relicensing
+}
+
+func (r *Report) HasRelicensings() bool {
+	return len(r.Relicensed) > 0
+}
+
+// SortedRelicensings returns all relicensed dependencies, sorted by dependency
+func (r *Report) SortedRelicensings() []Relicensing {
+	relicensings := lo.Values(r.Relicensed)
+	sort.Slice(relicensings, func(i, j int) bool {
+		return relicensings[i].Dependency < relicensings[j].Dependency
+	})
+	return relicensings
+}
+
+// ProjectNames returns the names of all projects in the report, sorted
+func (r *Report) ProjectNames() []string {
+	projects := lo.Keys(r.Projects)
+	sort.Strings(projects)
+	return projects
+}
+
+func mergeLicenseMaps(dst, src map[string][]string, skip func(dependency string) bool) map[string][]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string][]string)
+	}
+
+	for license, dependencies := range src {
+		dependencies = lo.Reject(dependencies, func(dependency string, _ int) bool {
+			return skip(dependency)
+		})
+		if len(dependencies) == 0 {
+			continue
+		}
+		// the same dependency can be used by several projects
+		dst[license] = lo.Uniq(append(dst[license], dependencies...))
+	}
+	return dst
+}
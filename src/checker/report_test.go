@@ -3,6 +3,7 @@ package checker
 import (
 	"testing"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -76,6 +77,23 @@ func TestRecordDecision(t *testing.T) {
 	)
 }
 
+func TestRecord(t *testing.T) {
+	report := &Report{}
+	report.Record(Decision{Dependency: "github.com/example/repo1", License: "MIT", Verdict: VerdictAllowed})
+	report.Record(Decision{Dependency: "github.com/example/repo2", License: "GPL", Verdict: VerdictDisallowed})
+	report.Record(Decision{Dependency: "github.com/example/repo3", License: "WTFPL", Verdict: VerdictUnknown})
+
+	assert.Equal(t, map[string][]string{"MIT": {"github.com/example/repo1"}}, report.Allowed)
+	assert.Equal(t, map[string][]string{"GPL": {"github.com/example/repo2"}}, report.Disallowed)
+	assert.Equal(t, map[string][]string{"WTFPL": {"github.com/example/repo3"}}, report.Unknown)
+
+	assert.Equal(
+		t,
+		[]string{"github.com/example/repo1", "github.com/example/repo2", "github.com/example/repo3"},
+		lo.Map(report.SortedDecisions(), func(d Decision, _ int) string { return d.Dependency }),
+	)
+}
+
 func TestHasDisallowedLicenses(t *testing.T) {
 	report := &Report{}
 	assert.False(t, report.HasDisallowedLicenses())
@@ -91,3 +109,77 @@ func TestHasUnknownLicenses(t *testing.T) {
 	report.RecordUnknownLicense("MIT", "github.com/example/repo")
 	assert.True(t, report.HasUnknownLicenses())
 }
+
+func TestRecordProject(t *testing.T) {
+	frontend := &Report{}
+	frontend.RecordAllowed("MIT", "left-pad")
+	frontend.RecordUnknownLicense("WTFPL", "is-even")
+
+	backend := &Report{}
+	backend.RecordAllowed("MIT", "left-pad")
+	backend.RecordDisallowed("GPL-3.0", "some-gpl-lib")
+
+	report := &Report{}
+	report.RecordProject("frontend", frontend)
+	report.RecordProject("backend", backend)
+
+	assert.Equal(t, []string{"backend", "frontend"}, report.ProjectNames())
+	assert.Same(t, frontend, report.Projects["frontend"])
+	assert.Same(t, backend, report.Projects["backend"])
+
+	// all projects are included in the top-level maps
+	assert.Equal(t, map[string][]string{"MIT": {"left-pad"}}, report.Allowed)
+	assert.Equal(t, map[string][]string{"GPL-3.0": {"some-gpl-lib"}}, report.Disallowed)
+	assert.Equal(t, map[string][]string{"WTFPL": {"is-even"}}, report.Unknown)
+}
+
+func TestRecordProject_FirstProjectWins(t *testing.T) {
+	backend := &Report{}
+	backend.Record(Decision{Dependency: "left-pad", License: "MIT", Verdict: VerdictAllowed})
+
+	frontend := &Report{}
+	frontend.Record(Decision{Dependency: "left-pad", License: "GPL-3.0", Verdict: VerdictDisallowed})
+	frontend.Record(Decision{Dependency: "is-even", License: "MIT", Verdict: VerdictAllowed})
+
+	report := &Report{}
+	report.RecordProject("backend", backend)
+	report.RecordProject("frontend", frontend)
+
+	assert.Equal(t, "MIT", report.Decisions["left-pad"].License)
+	assert.Equal(t, map[string][]string{"MIT": {"left-pad", "is-even"}}, report.Allowed)
+	assert.Empty(t, report.Disallowed)
+}
+
+func TestRecordChangedLicenseText(t *testing.T) {
+	report := &Report{}
+	assert.False(t, report.HasChangedLicenseTexts())
+
+	report.RecordChangedLicenseText("some-dependency", "MIT")
+	assert.True(t, report.HasChangedLicenseTexts())
+	assert.Equal(t, map[string]string{"some-dependency": "MIT"}, report.ChangedLicenseTexts)
+}
+
+func TestRecordVersions(t *testing.T) {
+	project := &Report{}
+	project.Record(Decision{Dependency: "left-pad", License: "MIT", Verdict: VerdictAllowed})
+
+	report := &Report{}
+	report.RecordProject("frontend", project)
+	report.RecordVersions(map[string]string{"left-pad": "1.3.0"})
+
+	assert.Equal(t, "1.3.0", report.Decisions["left-pad"].Version)
+	assert.Equal(t, "1.3.0", report.Projects["frontend"].Decisions["left-pad"].Version)
+}
+
+func TestRecordRelicensing(t *testing.T) {
+	report := &Report{}
+	assert.False(t, report.HasRelicensings())
+
+	report.RecordRelicensing(Relicensing{Dependency: "b", PreviousLicense: "MIT", License: "BUSL-1.1"})
+	report.RecordRelicensing(Relicensing{Dependency: "a", PreviousLicense: "MIT", License: "Apache-2.0"})
+	assert.True(t, report.HasRelicensings())
+	assert.Equal(t, []Relicensing{
+		{Dependency: "a", PreviousLicense: "MIT", License: "Apache-2.0"},
+		{Dependency: "b", PreviousLicense: "MIT", License: "BUSL-1.1"},
+	}, report.SortedRelicensings())
+}
@@ -0,0 +1,75 @@
+package collector
+
+// NoAssertion is the license of dependencies whose license couldn't be
+// determined. The name is borrowed from SPDX.
+const NoAssertion = "NOASSERTION"
+
+// Dependency is a third-party dependency of the project being checked
+type Dependency struct {
+	Name string
+	// The version of the dependency, if the collector knows it
+	Version string
+	// The license expression the dependency is distributed under, e.g. `MIT`
+	// or `MIT || Apache-2.0`. Empty if the dependency doesn't declare one.
+	License string
+	// Path to the dependency's license text, if the collector knows where it is
+	LicenseFile string
+	// Where the dependency is declared, e.g. a line in a lockfile. Nil if the
+	// collector doesn't know.
+	Location *Location
+}
+
+// Location points at a line in a file, typically a manifest or lockfile
+type Location struct {
+	Path string
+	// 1-based line number, 0 if only the file is known
+	Line int
+}
+
+// Collector finds the dependencies of a project and their licenses
+type Collector interface {
+	Collect() ([]Dependency, error)
+}
+
+// ToLicenseMap converts a list of dependencies to the dependency -> license
+// map the checker works with
+func ToLicenseMap(dependencies []Dependency) map[string]string {
+	licenses := make(map[string]string, len(dependencies))
+	for _, dependency := range dependencies {
+		licenses[dependency.Name] = LicenseExpression(dependency)
+	}
+	return licenses
+}
+
+// LicenseExpression returns the license expression the checker sees for a
+// dependency, which is NoAssertion if the dependency doesn't declare one
+func LicenseExpression(dependency Dependency) string {
+	if dependency.License == "" {
+		return NoAssertion
+	}
+	return dependency.License
+}
+
+// ToVersionMap returns the version of every dependency whose version is
+// known, keyed by dependency
+func ToVersionMap(dependencies []Dependency) map[string]string {
+	versions := make(map[string]string)
+	for _, dependency := range dependencies {
+		if dependency.Version != "" {
+			versions[dependency.Name] = dependency.Version
+		}
+	}
+	return versions
+}
+
+// ToLocationMap returns the location of every dependency whose location is
+// known, keyed by dependency
+func ToLocationMap(dependencies []Dependency) map[string]Location {
+	locations := make(map[string]Location)
+	for _, dependency := range dependencies {
+		if dependency.Location != nil {
+			locations[dependency.Name] = *dependency.Location
+		}
+	}
+	return locations
+}
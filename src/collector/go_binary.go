@@ -0,0 +1,146 @@
+package collector
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"unicode"
+)
+
+// Common names of license files, in order of preference
+var licenseFileNames = []string{
+	"LICENSE", "LICENSE.md", "LICENSE.txt",
+	"LICENCE", "LICENCE.md", "LICENCE.txt",
+	"COPYING", "COPYING.md", "COPYING.txt",
+	"license", "license.md", "license.txt",
+}
+
+// GoBinaryCollector lists the modules compiled into a Go executable, using the
+// build info the Go toolchain embeds in every binary. The licenses of the
+// modules are read from their license files, found either in a vendor
+// directory or the local module cache.
+//
+// Modules whose license file can't be found get the license NOASSERTION.
+type GoBinaryCollector struct {
+	binary string
+
+	// Directory containing vendored modules, like the `vendor` directory of a
+	// Go project. It's searched before the module cache. Optional.
+	VendorDir string
+	// The Go module cache. Defaults to $GOMODCACHE, or $GOPATH/pkg/mod.
+	ModCacheDir string
+}
+
+func NewGoBinaryCollector(binary string) *GoBinaryCollector {
+	return &GoBinaryCollector{
+		binary:      binary,
+		ModCacheDir: defaultModCacheDir(),
+	}
+}
+
+func defaultModCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		gopath = filepath.Join(home, "go")
+	}
+	// GOPATH can be a list of paths, the module cache lives in the first one
+	gopath = filepath.SplitList(gopath)[0]
+
+	return filepath.Join(gopath, "pkg", "mod")
+}
+
+func (c *GoBinaryCollector) Collect() ([]Dependency, error) {
+	info, err := buildinfo.ReadFile(c.binary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build info from %s: %w", c.binary, err)
+	}
+
+	var dependencies []Dependency
+	for _, module := range info.Deps {
+		dependency := Dependency{
+			Name:    module.Path,
+			Version: module.Version,
+		}
+
+		dependency.LicenseFile = c.findLicenseFile(module)
+		if dependency.LicenseFile == "" {
+			slog.Warn("Couldn't find the license of module", "module", module.Path, "version", module.Version)
+			dependency.License = NoAssertion
+		}
+
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies, nil
+}
+
+// findLicenseFile returns the path to the license file of a module, or an
+// empty string if it can't be found
+func (c *GoBinaryCollector) findLicenseFile(module *debug.Module) string {
+	var candidateDirs []string
+	if c.VendorDir != "" {
+		// replaced modules are vendored under their original path
+		candidateDirs = append(candidateDirs, filepath.Join(c.VendorDir, filepath.FromSlash(module.Path)))
+	}
+
+	// the code in the binary comes from the replacement, so that's where
+	// the license is
+	source := module
+	if module.Replace != nil {
+		source = module.Replace
+	}
+	if source.Version == "" {
+		// modules replaced by local directories have no version, and the
+		// path is a directory on disk
+		candidateDirs = append(candidateDirs, source.Path)
+	} else if c.ModCacheDir != "" {
+		dirName := escapeModulePath(source.Path) + "@" + escapeModulePath(source.Version)
+		candidateDirs = append(candidateDirs, filepath.Join(c.ModCacheDir, filepath.FromSlash(dirName)))
+	}
+
+	for _, dir := range candidateDirs {
+		if licenseFile := findLicenseFileInDir(dir); licenseFile != "" {
+			return licenseFile
+		}
+	}
+	return ""
+}
+
+func findLicenseFileInDir(dir string) string {
+	for _, name := range licenseFileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// escapeModulePath escapes a module path or version the way the module cache
+// does, to keep paths unique on case-insensitive file systems. Upper case
+// letters are replaced by an exclamation mark followed by the lower case
+// letter, e.g. `github.com/Azure/go` becomes `github.com/!azure/go`.
+func escapeModulePath(path string) string {
+	var sb strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			sb.WriteRune('!')
+			sb.WriteRune(unicode.ToLower(r))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeModulePath(t *testing.T) {
+	assert.Equal(t, "github.com/!azure/azure-sdk-for-go", escapeModulePath("github.com/Azure/azure-sdk-for-go"))
+	assert.Equal(t, "github.com/!burnt!sushi/toml", escapeModulePath("github.com/BurntSushi/toml"))
+	assert.Equal(t, "v1.2.3-!r!c1", escapeModulePath("v1.2.3-RC1"))
+}
+
+func TestFindLicenseFile_ReplacedModule(t *testing.T) {
+	module := &debug.Module{
+		Path:    "example.com/original",
+		Version: "v1.0.0",
+		Replace: &debug.Module{Path: "example.com/fork", Version: "v1.0.1"},
+	}
+
+	vendorDir := t.TempDir()
+	modCacheDir := t.TempDir()
+	cached := filepath.Join(modCacheDir, "example.com", "fork@v1.0.1", "LICENSE")
+	require.NoError(t, os.MkdirAll(filepath.Dir(cached), 0755))
+	require.NoError(t, os.WriteFile(cached, []byte("MIT License"), 0644))
+
+	sut := &GoBinaryCollector{VendorDir: vendorDir, ModCacheDir: modCacheDir}
+
+	// the replacement is only in the module cache
+	assert.Equal(t, cached, sut.findLicenseFile(module))
+
+	// replaced modules are vendored under their original path
+	vendored := filepath.Join(vendorDir, "example.com", "original", "LICENSE")
+	require.NoError(t, os.MkdirAll(filepath.Dir(vendored), 0755))
+	require.NoError(t, os.WriteFile(vendored, []byte("MIT License"), 0644))
+	assert.Equal(t, vendored, sut.findLicenseFile(module))
+}
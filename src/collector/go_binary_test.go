@@ -0,0 +1,63 @@
+package collector_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/collector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoBinaryCollector_Collect(t *testing.T) {
+	// The test binary is a Go binary like any other, with testify and its
+	// dependencies compiled into it
+	binary, err := os.Executable()
+	require.NoError(t, err)
+
+	modCacheDir := t.TempDir()
+	writeFile(t, filepath.Join(modCacheDir, "github.com", "stretchr", "testify@v1.9.0", "LICENSE"), "MIT License")
+
+	vendorDir := t.TempDir()
+	writeFile(t, filepath.Join(vendorDir, "gopkg.in", "yaml.v3", "LICENSE.md"), "Apache License")
+
+	sut := collector.NewGoBinaryCollector(binary)
+	sut.ModCacheDir = modCacheDir
+	sut.VendorDir = vendorDir
+
+	dependencies, err := sut.Collect()
+	require.NoError(t, err)
+
+	byName := make(map[string]collector.Dependency)
+	for _, dependency := range dependencies {
+		byName[dependency.Name] = dependency
+	}
+
+	assert.Equal(t, collector.Dependency{
+		Name:        "github.com/stretchr/testify",
+		Version:     "v1.9.0",
+		LicenseFile: filepath.Join(modCacheDir, "github.com", "stretchr", "testify@v1.9.0", "LICENSE"),
+	}, byName["github.com/stretchr/testify"])
+
+	assert.Equal(t, filepath.Join(vendorDir, "gopkg.in", "yaml.v3", "LICENSE.md"), byName["gopkg.in/yaml.v3"].LicenseFile)
+
+	unresolved := byName["github.com/davecgh/go-spew"]
+	assert.Equal(t, collector.NoAssertion, unresolved.License)
+	assert.Empty(t, unresolved.LicenseFile)
+}
+
+func TestGoBinaryCollector_NotAGoBinary(t *testing.T) {
+	notABinary := filepath.Join(t.TempDir(), "not-a-binary")
+	writeFile(t, notABinary, "#!/bin/sh\necho hi\n")
+
+	_, err := collector.NewGoBinaryCollector(notABinary).Collect()
+	assert.Error(t, err)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
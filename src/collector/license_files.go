@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/eriklarko/license-checker/src/licenseclassifier"
+)
+
+// WithLicenseClassification wraps a collector so that dependencies without a
+// declared license get one by classifying their license file, if they have
+// one. Dependencies whose license file can't be confidently classified get
+// the license NOASSERTION.
+func WithLicenseClassification(c Collector, classifier *licenseclassifier.Classifier) Collector {
+	return &classifyingCollector{
+		collector:  c,
+		classifier: classifier,
+	}
+}
+
+type classifyingCollector struct {
+	collector  Collector
+	classifier *licenseclassifier.Classifier
+}
+
+func (c *classifyingCollector) Collect() ([]Dependency, error) {
+	dependencies, err := c.collector.Collect()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, dependency := range dependencies {
+		if dependency.License != "" || dependency.LicenseFile == "" {
+			continue
+		}
+
+		dependencies[i].License = c.classify(dependency)
+	}
+
+	return dependencies, nil
+}
+
+func (c *classifyingCollector) classify(dependency Dependency) string {
+	match, err := c.classifier.ClassifyFile(dependency.LicenseFile)
+	if err != nil {
+		slog.Warn("Failed to classify license file", "dependency", dependency.Name, "error", err)
+		return NoAssertion
+	}
+
+	if !match.IsConfident() {
+		slog.Warn(
+			"License file doesn't closely resemble any known license",
+			"dependency", dependency.Name,
+			"file", dependency.LicenseFile,
+			"closest_match", match.License,
+			"confidence", fmt.Sprintf("%.2f", match.Confidence),
+		)
+		return NoAssertion
+	}
+
+	slog.Info(
+		"Classified license file",
+		"dependency", dependency.Name,
+		"license", match.License,
+		"confidence", fmt.Sprintf("%.2f", match.Confidence),
+	)
+	return match.License
+}
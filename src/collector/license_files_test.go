@@ -0,0 +1,67 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/collector"
+	helpers_test "github.com/eriklarko/license-checker/src/helpers"
+	"github.com/eriklarko/license-checker/src/licenseclassifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticCollector []collector.Dependency
+
+func (s staticCollector) Collect() ([]collector.Dependency, error) {
+	return s, nil
+}
+
+func TestWithLicenseClassification(t *testing.T) {
+	classifier, err := licenseclassifier.New()
+	require.NoError(t, err)
+
+	mitFile := helpers_test.CreateTempFileWithContents(t, `Copyright (c) 2024 Someone
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`)
+	gibberishFile := helpers_test.CreateTempFileWithContents(t, "All your base are belong to us")
+
+	sut := collector.WithLicenseClassification(
+		staticCollector{
+			{Name: "declared", License: "Apache-2.0", LicenseFile: mitFile},
+			{Name: "undeclared", LicenseFile: mitFile},
+			{Name: "unrecognizable", LicenseFile: gibberishFile},
+			{Name: "missing-file", LicenseFile: "does-not-exist"},
+			{Name: "no-file"},
+		},
+		classifier,
+	)
+
+	dependencies, err := sut.Collect()
+	require.NoError(t, err)
+
+	licenses := collector.ToLicenseMap(dependencies)
+	assert.Equal(t, map[string]string{
+		"declared":       "Apache-2.0", // declared licenses win over license files
+		"undeclared":     "MIT",
+		"unrecognizable": collector.NoAssertion,
+		"missing-file":   collector.NoAssertion,
+		"no-file":        collector.NoAssertion,
+	}, licenses)
+}
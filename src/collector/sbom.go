@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"os"
+	"strings"
+
+	"github.com/eriklarko/license-checker/src/sbom"
+)
+
+// SBOMCollector reads the dependencies from an SPDX or CycloneDX document
+// instead of asking a package manager. The license of each dependency is the
+// concluded license of the package if there is one, otherwise the declared
+// license.
+type SBOMCollector struct {
+	path string
+}
+
+func NewSBOMCollector(path string) *SBOMCollector {
+	return &SBOMCollector{path: path}
+}
+
+func (s *SBOMCollector) Collect() ([]Dependency, error) {
+	packages, err := sbom.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	// the locations are a nicety, so the dependencies are still returned
+	// without them if the file can't be read again
+	var lines []string
+	if content, err := os.ReadFile(s.path); err == nil {
+		lines = strings.Split(string(content), "\n")
+	}
+	searchFrom := 0
+
+	dependencies := make([]Dependency, 0, len(packages))
+	for _, p := range packages {
+		dependency := Dependency{
+			Name:    p.Name,
+			Version: p.Version,
+			License: sbom.ToCheckerExpression(p.License),
+		}
+
+		line := findPackageLine(lines, searchFrom, p.Name)
+		if line > 0 {
+			dependency.Location = &Location{Path: s.path, Line: line}
+			searchFrom = line
+		}
+
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies, nil
+}
+
+// findPackageLine returns the 1-based number of the line naming the package,
+// or 0 if it can't be found. The packages are listed in document order, so
+// the search starts after the line of the previous package.
+//
+// This is a best effort search that works for SBOMs with one field per line,
+// which is what most tools generate.
+func findPackageLine(lines []string, searchFrom int, name string) int {
+	// CycloneDX names are `group/name`, but only the name is on the line
+	shortName := name[strings.LastIndex(name, "/")+1:]
+	needles := []string{
+		`"` + name + `"`,
+		`"` + shortName + `"`,
+		"<name>" + shortName + "</name>",
+		"PackageName: " + name,
+	}
+
+	for _, start := range []int{searchFrom, 0} {
+		for i := start; i < len(lines); i++ {
+			for _, needle := range needles {
+				if strings.Contains(lines[i], needle) {
+					return i + 1
+				}
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/collector"
+	helpers_test "github.com/eriklarko/license-checker/src/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSBOMCollector_Collect(t *testing.T) {
+	path := helpers_test.CreateTempFileWithContents(t, `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"type": "library", "name": "dual", "version": "1.0.0", "licenses": [{"expression": "MIT OR Apache-2.0"}]},
+    {"type": "library", "name": "unknown", "version": "2.0.0"}
+  ]
+}`)
+
+	dependencies, err := collector.NewSBOMCollector(path).Collect()
+	require.NoError(t, err)
+
+	assert.Equal(t, []collector.Dependency{
+		{Name: "dual", Version: "1.0.0", License: "MIT || Apache-2.0", Location: &collector.Location{Path: path, Line: 5}},
+		{Name: "unknown", Version: "2.0.0", License: collector.NoAssertion, Location: &collector.Location{Path: path, Line: 6}},
+	}, dependencies)
+}
@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ScriptCollector gets the dependencies by running a script. The script is
+// expected to print one dependency per line, in the format
+//
+//	dependency,license[,path-to-license-file[,location[,version]]]
+//
+// The license may be left empty if the path to the license file is given. The
+// location is where the dependency is declared, as `path` or `path:line`.
+// Relative paths are relative to the directory the script is run in. Optional
+// columns may be left empty, and columns after the last one are ignored.
+//
+// The optional columns can also be named, like `license-file=LICENSE`,
+// `location=go.mod:12` or `version=1.2.3`. Named columns can be in any order
+// after the license, so that only the columns that are known need to be
+// printed, e.g. `dependency,MIT,version=1.2.3`.
+//
+// For JS, look at https://github.com/franciscop/legally
+type ScriptCollector struct {
+	script string
+
+	// The directory to run the script in. Defaults to the current working
+	// directory.
+	Dir string
+}
+
+func NewScriptCollector(script string) *ScriptCollector {
+	return &ScriptCollector{script: script}
+}
+
+func (s *ScriptCollector) Collect() ([]Dependency, error) {
+	script := s.script
+	if s.Dir != "" && strings.ContainsRune(script, filepath.Separator) {
+		// relative paths would otherwise be resolved relative to s.Dir.
+		// Scripts without a separator are looked up in PATH instead.
+		absPath, err := filepath.Abs(script)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path of script %s: %w", script, err)
+		}
+		script = absPath
+	}
+
+	cmd := exec.Command(script)
+	cmd.Dir = s.Dir
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdout for script %s: %w", s.script, err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start command %s: %w", s.script, err)
+	}
+
+	var dependencies []Dependency
+	var parseErr error
+	scanner := bufio.NewScanner(stdout)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || parseErr != nil {
+			// keep reading even if parsing failed so the script isn't
+			// blocked writing to a full pipe
+			continue
+		}
+
+		dependency, err := parseScriptLine(line)
+		if err != nil {
+			parseErr = err
+			continue
+		}
+		// the script runs in s.Dir, so the paths it prints are relative to it
+		if dependency.Location != nil {
+			dependency.Location.Path = s.resolve(dependency.Location.Path)
+		}
+		dependency.LicenseFile = s.resolve(dependency.LicenseFile)
+		dependencies = append(dependencies, dependency)
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for command %s to finish: %w", s.script, err)
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("failed to parse output of %s: %w", s.script, parseErr)
+	}
+
+	return dependencies, nil
+}
+
+// resolve makes a relative path printed by the script relative to the
+// directory the script runs in
+func (s *ScriptCollector) resolve(path string) string {
+	if path == "" || s.Dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.Dir, path)
+}
+
+// the optional columns of the script output, in their positional order
+var scriptColumns = []string{"license-file", "location", "version"}
+
+// matches named columns like `version=1.2.3`, but not paths like `a=b/LICENSE`
+var namedColumnRegex = regexp.MustCompile(`^([a-z][a-z-]*)=(.*)$`)
+
+func parseScriptLine(line string) (Dependency, error) {
+	parts := strings.Split(line, ",")
+	if len(parts) < 2 {
+		return Dependency{}, fmt.Errorf("expected 'dependency,license[,license-file[,location[,version]]]' but got '%s'", line)
+	}
+
+	dependency := Dependency{
+		Name:    parts[0],
+		License: strings.TrimSpace(parts[1]),
+	}
+	for i, part := range parts[2:] {
+		column, value := "", strings.TrimSpace(part)
+		if match := namedColumnRegex.FindStringSubmatch(value); match != nil {
+			// unknown names are ignored, like extra columns
+			column, value = match[1], match[2]
+		} else if i < len(scriptColumns) {
+			column = scriptColumns[i]
+		}
+
+		switch column {
+		case "license-file":
+			dependency.LicenseFile = value
+		case "location":
+			if value == "" {
+				continue
+			}
+			location, err := parseLocation(value)
+			if err != nil {
+				return Dependency{}, fmt.Errorf("invalid location in '%s': %w", line, err)
+			}
+			dependency.Location = location
+		case "version":
+			dependency.Version = value
+		}
+	}
+
+	return dependency, nil
+}
+
+// parseLocation parses locations in the format `path` or `path:line`
+func parseLocation(s string) (*Location, error) {
+	path, line, found := strings.Cut(s, ":")
+	if !found {
+		return &Location{Path: s}, nil
+	}
+
+	lineNumber, err := strconv.Atoi(line)
+	if err != nil || lineNumber < 1 {
+		return nil, fmt.Errorf("expected a line number after ':' but got '%s'", line)
+	}
+	return &Location{Path: path, Line: lineNumber}, nil
+}
@@ -0,0 +1,183 @@
+package collector_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/collector"
+	helpers_test "github.com/eriklarko/license-checker/src/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptCollector_Collect(t *testing.T) {
+	t.Run("parses declared licenses and license files", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "dep-1,MIT"
+echo ""
+echo "dep-2,,/path/to/LICENSE"
+echo "dep-3,Apache-2.0,/path/to/other/LICENSE"
+`)
+
+		dependencies, err := collector.NewScriptCollector(script).Collect()
+		require.NoError(t, err)
+
+		assert.Equal(t, []collector.Dependency{
+			{Name: "dep-1", License: "MIT"},
+			{Name: "dep-2", License: "", LicenseFile: "/path/to/LICENSE"},
+			{Name: "dep-3", License: "Apache-2.0", LicenseFile: "/path/to/other/LICENSE"},
+		}, dependencies)
+	})
+
+	t.Run("parses locations", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "dep-1,MIT,,package-lock.json:12"
+echo "dep-2,MIT,,/abs/go.mod"
+`)
+		dir := t.TempDir()
+
+		sut := collector.NewScriptCollector(script)
+		sut.Dir = dir
+		dependencies, err := sut.Collect()
+		require.NoError(t, err)
+
+		assert.Equal(t, []collector.Dependency{
+			{Name: "dep-1", License: "MIT", Location: &collector.Location{Path: filepath.Join(dir, "package-lock.json"), Line: 12}},
+			{Name: "dep-2", License: "MIT", Location: &collector.Location{Path: "/abs/go.mod"}},
+		}, dependencies)
+	})
+
+	t.Run("resolves license files relative to the directory", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "dep-1,MIT,vendor/dep-1/LICENSE"
+echo "dep-2,MIT,/abs/LICENSE"
+`)
+		dir := t.TempDir()
+
+		sut := collector.NewScriptCollector(script)
+		sut.Dir = dir
+		dependencies, err := sut.Collect()
+		require.NoError(t, err)
+
+		assert.Equal(t, []collector.Dependency{
+			{Name: "dep-1", License: "MIT", LicenseFile: filepath.Join(dir, "vendor/dep-1/LICENSE")},
+			{Name: "dep-2", License: "MIT", LicenseFile: "/abs/LICENSE"},
+		}, dependencies)
+	})
+
+	t.Run("parses versions", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "dep-1,MIT,,,1.2.3"
+echo "dep-2,MIT,/path/to/LICENSE,/abs/go.mod, v0.1.0"
+`)
+
+		dependencies, err := collector.NewScriptCollector(script).Collect()
+		require.NoError(t, err)
+
+		assert.Equal(t, []collector.Dependency{
+			{Name: "dep-1", Version: "1.2.3", License: "MIT"},
+			{Name: "dep-2", Version: "v0.1.0", License: "MIT", LicenseFile: "/path/to/LICENSE", Location: &collector.Location{Path: "/abs/go.mod"}},
+		}, dependencies)
+	})
+
+	t.Run("parses named columns", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "dep-1,MIT,version=1.2.3"
+echo "dep-2,MIT,location=/abs/go.mod:3, version=v0.1.0,license-file=/path/to/LICENSE"
+echo "dep-3,MIT,/path/to/LICENSE,version=2.0.0"
+echo "dep-4,MIT,/path/to/a=b/LICENSE,something=else"
+`)
+
+		dependencies, err := collector.NewScriptCollector(script).Collect()
+		require.NoError(t, err)
+
+		assert.Equal(t, []collector.Dependency{
+			{Name: "dep-1", Version: "1.2.3", License: "MIT"},
+			{Name: "dep-2", Version: "v0.1.0", License: "MIT", LicenseFile: "/path/to/LICENSE", Location: &collector.Location{Path: "/abs/go.mod", Line: 3}},
+			{Name: "dep-3", Version: "2.0.0", License: "MIT", LicenseFile: "/path/to/LICENSE"},
+			{Name: "dep-4", License: "MIT", LicenseFile: "/path/to/a=b/LICENSE"},
+		}, dependencies)
+	})
+
+	t.Run("ignores extra columns", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "dep-1,MIT,,,1.2.3,something,else"
+`)
+
+		dependencies, err := collector.NewScriptCollector(script).Collect()
+		require.NoError(t, err)
+
+		assert.Equal(t, []collector.Dependency{
+			{Name: "dep-1", Version: "1.2.3", License: "MIT"},
+		}, dependencies)
+	})
+
+	t.Run("invalid line number in location", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "dep-1,MIT,,package-lock.json:abc"
+`)
+
+		_, err := collector.NewScriptCollector(script).Collect()
+		assert.Error(t, err)
+	})
+
+	t.Run("runs script in the given directory", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "$(basename "$(pwd)"),MIT"
+`)
+		dir := filepath.Join(t.TempDir(), "some-project")
+		require.NoError(t, os.Mkdir(dir, 0755))
+
+		sut := collector.NewScriptCollector(script)
+		sut.Dir = dir
+		dependencies, err := sut.Collect()
+		require.NoError(t, err)
+
+		assert.Equal(t, []collector.Dependency{{Name: "some-project", License: "MIT"}}, dependencies)
+	})
+
+	t.Run("finds scripts without a path in PATH", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "dep-1,MIT"
+`)
+		t.Setenv("PATH", filepath.Dir(script)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		sut := collector.NewScriptCollector(filepath.Base(script))
+		sut.Dir = t.TempDir()
+		dependencies, err := sut.Collect()
+		require.NoError(t, err)
+
+		assert.Equal(t, []collector.Dependency{{Name: "dep-1", License: "MIT"}}, dependencies)
+	})
+
+	t.Run("malformed line", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+echo "dep-1"
+`)
+
+		_, err := collector.NewScriptCollector(script).Collect()
+		assert.Error(t, err)
+	})
+
+	t.Run("failing script", func(t *testing.T) {
+		script := helpers_test.CreateTempScript(t, `#!/bin/sh
+exit 1
+`)
+
+		_, err := collector.NewScriptCollector(script).Collect()
+		assert.Error(t, err)
+	})
+}
+
+func TestToLicenseMap(t *testing.T) {
+	licenses := collector.ToLicenseMap([]collector.Dependency{
+		{Name: "dep-1", License: "MIT"},
+		{Name: "dep-2", License: ""},
+	})
+
+	assert.Equal(t, map[string]string{
+		"dep-1": "MIT",
+		"dep-2": collector.NoAssertion,
+	}, licenses)
+}
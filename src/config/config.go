@@ -2,9 +2,12 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/eriklarko/license-checker/src/atomicfile"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,6 +16,18 @@ type Config struct {
 	LicensesScript string `yaml:"licenses-script"`
 	LicensesFile   string `yaml:"licenses-file"`
 	CacheDir       string `yaml:"cache-dir"`
+	// hashes of the license texts of all dependencies, to detect license
+	// texts that change without the declared license changing. Meant to be
+	// committed.
+	LicenseTextsLockfile string `yaml:"license-texts-lockfile"`
+	// the version and license of every dependency the last time it was
+	// checked, to detect dependencies that are relicensed in an upgrade.
+	// Meant to be committed.
+	LicenseHistoryFile string `yaml:"license-history-file"`
+	// the problems that are tolerated because they were there when the
+	// baseline was taken with the `baseline` command. If the file exists,
+	// only new problems fail the check. Meant to be committed.
+	BaselineFile string `yaml:"baseline-file"`
 
 	// optional values
 	CuratedListsSource  string `yaml:"curated-list-source"`
@@ -21,6 +36,28 @@ type Config struct {
 	CuratedScriptsSource  string `yaml:"curated-scripts-source,omitempty"`
 	SelectedCuratedScript string `yaml:"selected-curated-script,omitempty"`
 
+	// check every project found below the current directory instead of only
+	// the current directory
+	Recursive bool `yaml:"recursive,omitempty"`
+	// gitignore-style patterns of paths to skip when looking for projects
+	// recursively
+	IgnoreGlobs []string `yaml:"ignore,omitempty"`
+
+	// directory of Markdown files with company-specific guidance on licenses,
+	// named after the SPDX IDs of the licenses, e.g. `GPL-3.0-only.md`
+	DescriptionsDir string `yaml:"descriptions-dir,omitempty"`
+	// HTTP endpoint serving license descriptions at `<url>/<SPDX ID>`
+	DescriptionsURL string `yaml:"descriptions-url,omitempty"`
+	// how long descriptions fetched from DescriptionsURL are cached
+	DescriptionsCacheTTL time.Duration `yaml:"descriptions-cache-ttl,omitempty"`
+
+	// write the unknown licenses found in non-interactive runs to
+	// PendingDecisionsFile, for reviewers to decide on
+	WritePendingDecisions bool `yaml:"write-pending-decisions,omitempty"`
+	// unknown licenses waiting for a decision. The decisions are moved into
+	// the licenses file with the `decide` command. Meant to be committed.
+	PendingDecisionsFile string `yaml:"pending-decisions-file,omitempty"`
+
 	// the file this config was read from
 	Path string `yaml:"-"` // not serialized
 }
@@ -65,6 +102,24 @@ func (c *Config) applyDefaults() {
 		c.LicensesFile = filepath.Join(c.CacheDir, "licenses.csv")
 	}
 
+	if c.LicenseTextsLockfile == "" {
+		c.LicenseTextsLockfile = filepath.Join(c.CacheDir, "license-texts.lock")
+	}
+	if c.LicenseHistoryFile == "" {
+		c.LicenseHistoryFile = filepath.Join(c.CacheDir, "license-history.csv")
+	}
+	if c.BaselineFile == "" {
+		c.BaselineFile = filepath.Join(c.CacheDir, "baseline.yaml")
+	}
+
+	if c.PendingDecisionsFile == "" {
+		c.PendingDecisionsFile = filepath.Join(c.CacheDir, "pending-decisions.yaml")
+	}
+
+	if c.DescriptionsCacheTTL == 0 {
+		c.DescriptionsCacheTTL = 24 * time.Hour
+	}
+
 	if c.CuratedListsSource == "" {
 		c.CuratedListsSource = "https://raw.githubusercontent.com/eriklarko/license-checker-go/refs/heads/main/lists/list-metadata.yaml"
 	}
@@ -108,28 +163,19 @@ func (c *Config) Validate() error {
 	return nil
 }
 
-// Write writes the config to a file.
+// Write writes the config to a file. The file is replaced atomically, so a
+// crash while writing doesn't leave a half-written config behind.
 func (c *Config) Write() error {
-	// Open file for writing, creating it if it doesn't exist. Using 0644 which
-	// grants the owner read and write access, while the group members and other
-	// system users only have read acces
-	file, err := os.OpenFile(c.Path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return fmt.Errorf("error opening/creating file %s: %w", c.Path, err)
-	}
-	defer file.Close()
-
-	// Make sure the file is only writeable by the owner if the config file
-	// already existed before this write
-	os.Chmod(c.Path, 0644)
-
-	enc := yaml.NewEncoder(file)
-	err = enc.Encode(c)
-	if err != nil {
-		return fmt.Errorf("failed encoding config as yaml: %w", err)
-	}
-
-	return nil
+	// Using 0644 which grants the owner read and write access, while the group
+	// members and other system users only have read access. Existing config
+	// files get these permissions too, so they're only writeable by the owner.
+	return atomicfile.WriteFileWithPerm(c.Path, 0644, func(w io.Writer) error {
+		enc := yaml.NewEncoder(w)
+		if err := enc.Encode(c); err != nil {
+			return fmt.Errorf("failed encoding config as yaml: %w", err)
+		}
+		return enc.Close()
+	})
 }
 
 // String returns the YAML representation of the Config struct.
@@ -0,0 +1,195 @@
+package packagemanagerdetector
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Detection describes why we think a project uses a package manager
+type Detection struct {
+	PackageManager string
+	// The files that made us think the package manager is used, relative to
+	// the project directory
+	Evidence []string
+	// How sure we are that the package manager is used, between 0 and 1
+	Confidence float64
+	// Whether the package manager's lockfile was found. Projects with a
+	// lockfile are almost certainly using the package manager.
+	HasLockfile bool
+}
+
+// How much each piece of evidence adds to the confidence of a detection
+const (
+	validManifestConfidence   = 0.6
+	invalidManifestConfidence = 0.2
+	lockfileConfidence        = 0.4
+	onlyLockfileConfidence    = 0.3
+)
+
+type packageManagerRule struct {
+	name string
+	// files declaring the project's dependencies
+	manifests []string
+	// files pinning the exact versions of the dependencies
+	lockfiles []string
+
+	// looksValid checks that the contents of a manifest really belong to this
+	// package manager. Manifests that don't look valid count as weak evidence,
+	// unless `requireValidManifest` is set in which case they don't count at
+	// all.
+	looksValid           func(content []byte) bool
+	requireValidManifest bool
+
+	// set for package managers sharing manifests with other package managers,
+	// where only the lockfile tells them apart
+	requireLockfile bool
+}
+
+var packageManagerRules = []packageManagerRule{
+	{
+		name:      "npm",
+		manifests: []string{"package.json"},
+		lockfiles: []string{"package-lock.json", "npm-shrinkwrap.json"},
+	},
+	{
+		name:      "yarn",
+		manifests: []string{"package.json"},
+		lockfiles: []string{"yarn.lock"},
+		// package.json on its own says nothing about yarn
+		requireLockfile: true,
+	},
+	{
+		name:      "pnpm",
+		manifests: []string{"package.json"},
+		lockfiles: []string{"pnpm-lock.yaml"},
+		// package.json on its own says nothing about pnpm
+		requireLockfile: true,
+	},
+	{
+		name:      "go modules",
+		manifests: []string{"go.mod"},
+		lockfiles: []string{"go.sum"},
+		looksValid: func(content []byte) bool {
+			return bytes.Contains(content, []byte("module "))
+		},
+	},
+	{
+		name:       "pip",
+		manifests:  []string{"requirements.txt"},
+		looksValid: hasNonCommentLines,
+	},
+	{
+		name:      "pipenv",
+		manifests: []string{"Pipfile"},
+		lockfiles: []string{"Pipfile.lock"},
+	},
+	{
+		name:      "poetry",
+		manifests: []string{"pyproject.toml"},
+		lockfiles: []string{"poetry.lock"},
+		looksValid: func(content []byte) bool {
+			return bytes.Contains(content, []byte("[tool.poetry"))
+		},
+		requireValidManifest: true,
+	},
+	{
+		name:      "maven",
+		manifests: []string{"pom.xml"},
+		looksValid: func(content []byte) bool {
+			return bytes.Contains(content, []byte("<project"))
+		},
+	},
+	{
+		name:      "gradle",
+		manifests: []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"},
+		lockfiles: []string{"gradle.lockfile"},
+	},
+}
+
+// detect returns nil if there is no evidence of the package manager in the
+// directory
+func (r packageManagerRule) detect(directory string) (*Detection, error) {
+	detection := &Detection{PackageManager: r.name}
+
+	manifestConfidence := 0.0
+	for _, manifest := range r.manifests {
+		path := filepath.Join(directory, manifest)
+		exists, err := FileExists(path)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		confidence := validManifestConfidence
+		if r.looksValid != nil {
+			valid, err := r.manifestLooksValid(path)
+			if err != nil {
+				return nil, err
+			}
+
+			if !valid && r.requireValidManifest {
+				continue
+			} else if !valid {
+				confidence = invalidManifestConfidence
+			}
+		}
+
+		detection.Evidence = append(detection.Evidence, manifest)
+		manifestConfidence = max(manifestConfidence, confidence)
+	}
+
+	for _, lockfile := range r.lockfiles {
+		exists, err := FileExists(filepath.Join(directory, lockfile))
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			detection.Evidence = append(detection.Evidence, lockfile)
+			detection.HasLockfile = true
+		}
+	}
+
+	switch {
+	case r.requireLockfile && !detection.HasLockfile:
+		return nil, nil
+	case manifestConfidence > 0 && detection.HasLockfile:
+		detection.Confidence = min(1, manifestConfidence+lockfileConfidence)
+	case manifestConfidence > 0:
+		detection.Confidence = manifestConfidence
+	case detection.HasLockfile:
+		detection.Confidence = onlyLockfileConfidence
+	default:
+		return nil, nil
+	}
+
+	return detection, nil
+}
+
+func (r packageManagerRule) manifestLooksValid(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return r.looksValid(content), nil
+}
+
+// hasNonCommentLines returns true if the content has at least one line that
+// is not empty or a comment
+func hasNonCommentLines(content []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
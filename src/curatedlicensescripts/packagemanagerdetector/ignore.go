@@ -0,0 +1,163 @@
+package packagemanagerdetector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Directories that never contain projects of their own, only dependencies or
+// VCS metadata
+var alwaysIgnoredDirectories = []string{"node_modules", "vendor", ".git"}
+
+// ignoreRule is a single gitignore-style pattern
+type ignoreRule struct {
+	// the directory the pattern is relative to
+	baseDir string
+	regex   *regexp.Regexp
+	// patterns starting with ! re-include paths excluded by earlier patterns
+	negated bool
+	// patterns ending with / only match directories
+	directoryOnly bool
+}
+
+// ignoreRules is an ordered list of ignore rules where the last matching rule
+// decides if a path is ignored or not, just like in git
+type ignoreRules []ignoreRule
+
+// newIgnoreRule parses a gitignore-style pattern. Returns nil if the pattern
+// is blank or a comment.
+func newIgnoreRule(baseDir, pattern string) (*ignoreRule, error) {
+	pattern = strings.TrimRight(pattern, " \t\r")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return nil, nil
+	}
+
+	rule := &ignoreRule{baseDir: baseDir}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negated = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.directoryOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	// Patterns with a slash anywhere but the end are relative to the base
+	// directory, others match at any depth
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	prefix := "^(?:.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+
+	regex, err := regexp.Compile(prefix + globToRegex(pattern) + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid ignore pattern '%s': %w", pattern, err)
+	}
+	rule.regex = regex
+
+	return rule, nil
+}
+
+// globToRegex converts a glob pattern with support for `**` to a regular
+// expression
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end == -1 {
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+func (r *ignoreRule) matches(path string, isDir bool) bool {
+	if r.directoryOnly && !isDir {
+		return false
+	}
+
+	relPath, err := filepath.Rel(r.baseDir, path)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		// the path isn't below the directory the rule applies to
+		return false
+	}
+
+	return r.regex.MatchString(filepath.ToSlash(relPath))
+}
+
+func (rules ignoreRules) isIgnored(path string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.matches(path, isDir) {
+			ignored = !rule.negated
+		}
+	}
+	return ignored
+}
+
+func parseIgnorePatterns(baseDir string, patterns []string) (ignoreRules, error) {
+	var rules ignoreRules
+	for _, pattern := range patterns {
+		rule, err := newIgnoreRule(baseDir, pattern)
+		if err != nil {
+			return nil, err
+		}
+		if rule != nil {
+			rules = append(rules, *rule)
+		}
+	}
+	return rules, nil
+}
+
+// readGitignore reads the .gitignore file in the given directory, if there is
+// one
+func readGitignore(dir string) (ignoreRules, error) {
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open .gitignore in %s: %w", dir, err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read .gitignore in %s: %w", dir, err)
+	}
+
+	return parseIgnorePatterns(dir, patterns)
+}
@@ -2,38 +2,108 @@ package packagemanagerdetector
 
 import (
 	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
 )
 
 type Service struct {
 	// Where to look for package manager files
 	Directory string
+
+	// Gitignore-style patterns of paths to skip when looking for projects
+	// recursively. The patterns are relative to `Directory`.
+	IgnoreGlobs []string
+}
+
+// Project is a directory containing files belonging to at least one package
+// manager
+type Project struct {
+	// Path to the project directory
+	Root string
+	// The package managers detected in the project, most likely first
+	PackageManagers []Detection
 }
 
 func New(directory string) *Service {
 	return &Service{Directory: directory}
 }
 
-func (s *Service) FindLikelyPackageManagers() ([]string, error) {
-	// if file exists, return the package manager
-	filesToPackageManager := map[string]string{
-		"npm":        "package.json",
-		"go modules": "go.mod",
-		"pip":        "requirements.txt",
-		"maven":      "pom.xml",
-		"gradle":     "build.gradle",
+// FindLikelyPackageManagers returns the package managers used in `Directory`,
+// most likely first
+func (s *Service) FindLikelyPackageManagers() ([]Detection, error) {
+	return detectPackageManagers(s.Directory)
+}
+
+// FindProjects walks `Directory` and all its subdirectories and returns every
+// directory a package manager is detected in. Dependency directories like
+// `node_modules` and `vendor` are skipped, as are paths ignored by
+// .gitignore files or `IgnoreGlobs`.
+func (s *Service) FindProjects() ([]Project, error) {
+	rules, err := parseIgnorePatterns(s.Directory, s.IgnoreGlobs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ignore globs: %w", err)
 	}
 
-	var detectedPackageManagers []string
-	for packageManager, file := range filesToPackageManager {
-		exists, err := FileExists(fmt.Sprintf("%s/%s", s.Directory, file))
+	var projects []Project
+	err = filepath.WalkDir(s.Directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+
+		if path != s.Directory {
+			if slices.Contains(alwaysIgnoredDirectories, entry.Name()) || rules.isIgnored(path, true) {
+				return filepath.SkipDir
+			}
+		}
+
+		gitignoreRules, err := readGitignore(path)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		rules = append(rules, gitignoreRules...)
 
-		if exists {
-			detectedPackageManagers = append(detectedPackageManagers, packageManager)
+		packageManagers, err := detectPackageManagers(path)
+		if err != nil {
+			return err
 		}
+		if len(packageManagers) > 0 {
+			projects = append(projects, Project{
+				Root:            path,
+				PackageManagers: packageManagers,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for projects in %s: %w", s.Directory, err)
 	}
 
-	return detectedPackageManagers, nil
+	return projects, nil
+}
+
+func detectPackageManagers(directory string) ([]Detection, error) {
+	var detections []Detection
+	for _, rule := range packageManagerRules {
+		detection, err := rule.detect(directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect %s: %w", rule.name, err)
+		}
+
+		if detection != nil {
+			detections = append(detections, *detection)
+		}
+	}
+
+	// most likely package manager first
+	sort.SliceStable(detections, func(i, j int) bool {
+		return detections[i].Confidence > detections[j].Confidence
+	})
+
+	return detections, nil
 }
@@ -51,9 +51,228 @@ func TestPackageManagerDetector_Detect(t *testing.T) {
 			sut := packagemanagerdetector.New(dir)
 
 			// Act
-			detectedManagers, err := sut.FindLikelyPackageManagers()
+			detections, err := sut.FindLikelyPackageManagers()
 			require.NoError(t, err)
-			assert.ElementsMatch(t, tt.expectedManagers, detectedManagers)
+			assert.ElementsMatch(t, tt.expectedManagers, packageManagerNames(detections))
+		})
+	}
+}
+
+func TestPackageManagerDetector_Confidence(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []packagemanagerdetector.Detection
+	}{
+		{
+			name: "Lockfile beats manifest only",
+			files: map[string]string{
+				"package.json": "{}",
+				"yarn.lock":    "",
+			},
+			expected: []packagemanagerdetector.Detection{
+				{PackageManager: "yarn", Evidence: []string{"package.json", "yarn.lock"}, Confidence: 1, HasLockfile: true},
+				{PackageManager: "npm", Evidence: []string{"package.json"}, Confidence: 0.6},
+			},
+		},
+		{
+			name: "Stray empty requirements.txt is weak evidence",
+			files: map[string]string{
+				"go.mod":           "module example.com/foo\n",
+				"go.sum":           "",
+				"requirements.txt": "# nothing here\n",
+			},
+			expected: []packagemanagerdetector.Detection{
+				{PackageManager: "go modules", Evidence: []string{"go.mod", "go.sum"}, Confidence: 1, HasLockfile: true},
+				{PackageManager: "pip", Evidence: []string{"requirements.txt"}, Confidence: 0.2},
+			},
+		},
+		{
+			name: "Real requirements.txt",
+			files: map[string]string{
+				"requirements.txt": "requests==2.32.3\n",
+			},
+			expected: []packagemanagerdetector.Detection{
+				{PackageManager: "pip", Evidence: []string{"requirements.txt"}, Confidence: 0.6},
+			},
+		},
+		{
+			name: "pyproject.toml without poetry section",
+			files: map[string]string{
+				"pyproject.toml": "[build-system]\nrequires = [\"setuptools\"]\n",
+				"Pipfile":        "",
+				"Pipfile.lock":   "",
+			},
+			expected: []packagemanagerdetector.Detection{
+				{PackageManager: "pipenv", Evidence: []string{"Pipfile", "Pipfile.lock"}, Confidence: 1, HasLockfile: true},
+			},
+		},
+		{
+			name: "Poetry",
+			files: map[string]string{
+				"pyproject.toml": "[tool.poetry]\nname = \"foo\"\n",
+				"poetry.lock":    "",
+			},
+			expected: []packagemanagerdetector.Detection{
+				{PackageManager: "poetry", Evidence: []string{"pyproject.toml", "poetry.lock"}, Confidence: 1, HasLockfile: true},
+			},
+		},
+		{
+			name: "Gradle with kotlin DSL",
+			files: map[string]string{
+				"build.gradle.kts":    "",
+				"settings.gradle.kts": "",
+			},
+			expected: []packagemanagerdetector.Detection{
+				{PackageManager: "gradle", Evidence: []string{"build.gradle.kts", "settings.gradle.kts"}, Confidence: 0.6},
+			},
+		},
+		{
+			name: "Only a lockfile",
+			files: map[string]string{
+				"pnpm-lock.yaml": "",
+			},
+			expected: []packagemanagerdetector.Detection{
+				{PackageManager: "pnpm", Evidence: []string{"pnpm-lock.yaml"}, Confidence: 0.3, HasLockfile: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for file, content := range tt.files {
+				err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644)
+				require.NoError(t, err)
+			}
+
+			sut := packagemanagerdetector.New(dir)
+
+			// Act
+			detections, err := sut.FindLikelyPackageManagers()
+			require.NoError(t, err)
+
+			require.Len(t, detections, len(tt.expected))
+			for i, expected := range tt.expected {
+				assert.Equal(t, expected.PackageManager, detections[i].PackageManager)
+				assert.Equal(t, expected.Evidence, detections[i].Evidence)
+				assert.InDelta(t, expected.Confidence, detections[i].Confidence, 0.001)
+				assert.Equal(t, expected.HasLockfile, detections[i].HasLockfile)
+			}
+		})
+	}
+}
+
+func packageManagerNames(detections []packagemanagerdetector.Detection) []string {
+	names := make([]string, len(detections))
+	for i, detection := range detections {
+		names[i] = detection.PackageManager
+	}
+	return names
+}
+
+func TestPackageManagerDetector_FindProjects(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		ignoreGlobs      []string
+		expectedProjects map[string][]string
+	}{
+		{
+			name:             "No projects",
+			files:            map[string]string{"README.md": ""},
+			expectedProjects: map[string][]string{},
+		},
+		{
+			name: "Nested projects",
+			files: map[string]string{
+				"package.json":                   "",
+				"services/api/go.mod":            "",
+				"services/api/package.json":      "",
+				"services/worker/pom.xml":        "",
+				"tools/scripts/requirements.txt": "",
+			},
+			expectedProjects: map[string][]string{
+				".":               {"npm"},
+				"services/api":    {"go modules", "npm"},
+				"services/worker": {"maven"},
+				"tools/scripts":   {"pip"},
+			},
+		},
+		{
+			name: "Dependency and VCS directories are skipped",
+			files: map[string]string{
+				"package.json":                       "",
+				"node_modules/left-pad/package.json": "",
+				"vendor/github.com/foo/bar/go.mod":   "",
+				".git/modules/sub/package.json":      "",
+			},
+			expectedProjects: map[string][]string{
+				".": {"npm"},
+			},
+		},
+		{
+			name: "Gitignored paths are skipped",
+			files: map[string]string{
+				".gitignore":                 "# build output\nbuild/\n*.tmp\n!keep.tmp\n",
+				"build/package.json":         "",
+				"a.tmp/package.json":         "",
+				"keep.tmp/package.json":      "",
+				"app/.gitignore":             "/generated\n",
+				"app/go.mod":                 "",
+				"app/generated/package.json": "",
+				"generated/package.json":     "",
+			},
+			expectedProjects: map[string][]string{
+				"keep.tmp":  {"npm"},
+				"app":       {"go modules"},
+				"generated": {"npm"}, // only app/generated is ignored
+			},
+		},
+		{
+			name: "Ignore globs",
+			files: map[string]string{
+				"go.mod":                         "",
+				"examples/basic/go.mod":          "",
+				"examples/advanced/go.mod":       "",
+				"test/fixtures/one/package.json": "",
+			},
+			ignoreGlobs: []string{"examples/**", "**/fixtures"},
+			expectedProjects: map[string][]string{
+				".": {"go modules"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for file, content := range tt.files {
+				filePath := filepath.Join(dir, file)
+				err := os.MkdirAll(filepath.Dir(filePath), 0755)
+				require.NoError(t, err)
+				err = os.WriteFile(filePath, []byte(content), 0644)
+				require.NoError(t, err)
+			}
+
+			sut := packagemanagerdetector.New(dir)
+			sut.IgnoreGlobs = tt.ignoreGlobs
+
+			// Act
+			projects, err := sut.FindProjects()
+			require.NoError(t, err)
+
+			detectedProjects := make(map[string][]string)
+			for _, project := range projects {
+				relPath, err := filepath.Rel(dir, project.Root)
+				require.NoError(t, err)
+				detectedProjects[filepath.ToSlash(relPath)] = packageManagerNames(project.PackageManagers)
+			}
+
+			assert.Len(t, detectedProjects, len(tt.expectedProjects))
+			for root, expectedManagers := range tt.expectedProjects {
+				assert.ElementsMatch(t, expectedManagers, detectedProjects[root], "project %s", root)
+			}
 		})
 	}
 }
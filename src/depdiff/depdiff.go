@@ -0,0 +1,125 @@
+// Package depdiff compares the dependencies of two revisions of a project
+package depdiff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+)
+
+// Change is a dependency that was added, removed or changed license between
+// the base and head revisions
+type Change struct {
+	Dependency  string
+	BaseVersion string
+	// Empty for added dependencies
+	BaseLicense string
+	HeadVersion string
+	// Empty for removed dependencies
+	HeadLicense string
+
+	// The verdict on the head license. Nil for removed dependencies.
+	Decision *checker.Decision
+}
+
+// Diff is the difference between the dependencies of two revisions
+type Diff struct {
+	Added          []Change
+	Removed        []Change
+	LicenseChanged []Change
+}
+
+// Compare returns the dependencies that were added, removed or changed
+// license. Dependencies whose version changed but license didn't are left
+// out.
+func Compare(base, head []collector.Dependency) *Diff {
+	baseLicenses := collector.ToLicenseMap(base)
+	headLicenses := collector.ToLicenseMap(head)
+	baseVersions := collector.ToVersionMap(base)
+	headVersions := collector.ToVersionMap(head)
+
+	diff := &Diff{}
+	for dependency, headLicense := range headLicenses {
+		baseLicense, found := baseLicenses[dependency]
+		change := Change{
+			Dependency:  dependency,
+			BaseVersion: baseVersions[dependency],
+			BaseLicense: baseLicense,
+			HeadVersion: headVersions[dependency],
+			HeadLicense: headLicense,
+		}
+
+		if !found {
+			diff.Added = append(diff.Added, change)
+		} else if baseLicense != headLicense {
+			diff.LicenseChanged = append(diff.LicenseChanged, change)
+		}
+	}
+	for dependency, baseLicense := range baseLicenses {
+		if _, found := headLicenses[dependency]; !found {
+			diff.Removed = append(diff.Removed, Change{
+				Dependency:  dependency,
+				BaseVersion: baseVersions[dependency],
+				BaseLicense: baseLicense,
+			})
+		}
+	}
+
+	sortChanges(diff.Added)
+	sortChanges(diff.Removed)
+	sortChanges(diff.LicenseChanged)
+	return diff
+}
+
+// Check decides if the licenses of the added and changed dependencies are
+// allowed
+func (d *Diff) Check(licenseChecker *checker.LicenseChecker) error {
+	licenses := make(map[string]string)
+	for _, change := range d.introduced() {
+		licenses[change.Dependency] = change.HeadLicense
+	}
+
+	report, err := licenseChecker.ValidateCurrentLicenses(licenses)
+	if err != nil {
+		return fmt.Errorf("failed to check the introduced licenses: %w", err)
+	}
+
+	for _, changes := range [][]Change{d.Added, d.LicenseChanged} {
+		for i := range changes {
+			decision := report.Decisions[changes[i].Dependency]
+			changes[i].Decision = &decision
+		}
+	}
+	return nil
+}
+
+// IsEmpty returns true if no dependency was added, removed or changed license
+func (d *Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.LicenseChanged) == 0
+}
+
+// Problems returns the added and changed dependencies whose licenses aren't
+// allowed. Check must be called first.
+func (d *Diff) Problems() []Change {
+	var problems []Change
+	for _, change := range d.introduced() {
+		if change.Decision != nil && change.Decision.Verdict != checker.VerdictAllowed {
+			problems = append(problems, change)
+		}
+	}
+	sortChanges(problems)
+	return problems
+}
+
+// introduced returns the changes that bring in a license
+func (d *Diff) introduced() []Change {
+	return append(append([]Change(nil), d.Added...), d.LicenseChanged...)
+}
+
+func sortChanges(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Dependency < changes[j].Dependency
+	})
+}
@@ -0,0 +1,66 @@
+package depdiff_test
+
+import (
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+	"github.com/eriklarko/license-checker/src/depdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	diff := depdiff.Compare(
+		[]collector.Dependency{
+			{Name: "unchanged", Version: "1.0.0", License: "MIT"},
+			{Name: "upgraded", Version: "1.0.0", License: "MIT"},
+			{Name: "relicensed", Version: "1.0.0", License: "MIT"},
+			{Name: "removed", Version: "1.0.0", License: "ISC"},
+		},
+		[]collector.Dependency{
+			{Name: "unchanged", Version: "1.0.0", License: "MIT"},
+			{Name: "upgraded", Version: "1.1.0", License: "MIT"},
+			{Name: "relicensed", Version: "2.0.0", License: "BUSL-1.1"},
+			{Name: "added", Version: "0.1.0"},
+		},
+	)
+
+	assert.Equal(t, []depdiff.Change{
+		{Dependency: "added", HeadVersion: "0.1.0", HeadLicense: collector.NoAssertion},
+	}, diff.Added)
+	assert.Equal(t, []depdiff.Change{
+		{Dependency: "removed", BaseVersion: "1.0.0", BaseLicense: "ISC"},
+	}, diff.Removed)
+	assert.Equal(t, []depdiff.Change{
+		{Dependency: "relicensed", BaseVersion: "1.0.0", BaseLicense: "MIT", HeadVersion: "2.0.0", HeadLicense: "BUSL-1.1"},
+	}, diff.LicenseChanged)
+}
+
+func TestCheck(t *testing.T) {
+	diff := depdiff.Compare(
+		[]collector.Dependency{
+			{Name: "relicensed", License: "MIT"},
+			{Name: "removed", License: "GPL-3.0-only"},
+		},
+		[]collector.Dependency{
+			{Name: "relicensed", License: "BUSL-1.1"},
+			{Name: "allowed", License: "MIT"},
+			{Name: "unknown", License: "ISC"},
+		},
+	)
+
+	lc := checker.NewFromLists([]string{"MIT"}, []string{"GPL-3.0-only", "BUSL-1.1"})
+	require.NoError(t, diff.Check(lc))
+
+	require.NotNil(t, diff.Added[0].Decision)
+	assert.Equal(t, checker.VerdictAllowed, diff.Added[0].Decision.Verdict)
+	assert.Equal(t, checker.VerdictDisallowed, diff.LicenseChanged[0].Decision.Verdict)
+	// removed dependencies don't matter
+	assert.Nil(t, diff.Removed[0].Decision)
+
+	problems := diff.Problems()
+	require.Len(t, problems, 2)
+	assert.Equal(t, "relicensed", problems[0].Dependency)
+	assert.Equal(t, "unknown", problems[1].Dependency)
+}
@@ -0,0 +1,91 @@
+// Package filelock takes advisory locks on files, so that concurrent runs of
+// the tool don't overwrite each other's changes when they read, modify and
+// write the same file
+package filelock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrLocked is returned by TryAcquire if another process holds the lock
+var ErrLocked = errors.New("file is locked by another process")
+
+// Lock is an advisory lock on a file. Other processes trying to lock the same
+// file wait until it's unlocked, or until the process holding it exits.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire blocks until it holds the lock on the file at path. The lock is taken
+// on a separate file in dir rather than on the file itself, since files are
+// replaced when they are written atomically.
+func Acquire(dir string, path string) (*Lock, error) {
+	return acquire(dir, path, true)
+}
+
+// TryAcquire is like Acquire, but returns ErrLocked instead of waiting if another
+// process holds the lock
+func TryAcquire(dir string, path string) (*Lock, error) {
+	return acquire(dir, path, false)
+}
+
+func acquire(dir string, path string, wait bool) (*Lock, error) {
+	lockPath, err := lockFilePath(dir, path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", lockPath, err)
+	}
+
+	if err := lock(file, wait); err != nil {
+		file.Close()
+		if errors.Is(err, ErrLocked) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("failed to unlock %s: %w", l.file.Name(), err)
+	}
+	return l.file.Close()
+}
+
+// lockFilePath returns the path of the file locked in place of the file at
+// path, creating the lock directory if needed. The lock directory ignores
+// itself in git, since it's usually inside the cache dir.
+func lockFilePath(dir string, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create lock directory %s: %w", dir, err)
+	}
+	gitignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(gitignore); os.IsNotExist(err) {
+		if err := os.WriteFile(gitignore, []byte("*\n"), 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", gitignore, err)
+		}
+	}
+
+	// the hash tells apart files with the same name in different directories
+	hash := sha256.Sum256([]byte(absPath))
+	name := fmt.Sprintf("%s-%s.lock", filepath.Base(absPath), hex.EncodeToString(hash[:4]))
+	return filepath.Join(dir, name), nil
+}
@@ -0,0 +1,75 @@
+//go:build unix
+
+package filelock_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eriklarko/license-checker/src/filelock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryAcquire(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "locks")
+	path := filepath.Join(t.TempDir(), "licenses.yaml")
+
+	lock, err := filelock.TryAcquire(dir, path)
+	require.NoError(t, err)
+
+	_, err = filelock.TryAcquire(dir, path)
+	assert.ErrorIs(t, err, filelock.ErrLocked)
+
+	other, err := filelock.TryAcquire(dir, filepath.Join(t.TempDir(), "licenses.yaml"))
+	require.NoError(t, err, "files with the same name in different directories have different locks")
+	require.NoError(t, other.Unlock())
+
+	require.NoError(t, lock.Unlock())
+	lock, err = filelock.TryAcquire(dir, path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock())
+}
+
+func TestAcquire_WaitsForLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "licenses.yaml")
+
+	lock, err := filelock.Acquire(dir, path)
+	require.NoError(t, err)
+
+	acquired := make(chan *filelock.Lock)
+	go func() {
+		lock, err := filelock.Acquire(dir, path)
+		assert.NoError(t, err)
+		acquired <- lock
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("lock was acquired while held by someone else")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, lock.Unlock())
+	select {
+	case lock := <-acquired:
+		require.NoError(t, lock.Unlock())
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock was not acquired after being released")
+	}
+}
+
+func TestAcquire_IgnoresLockDirectoryInGit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "locks")
+
+	lock, err := filelock.Acquire(dir, "licenses.yaml")
+	require.NoError(t, err)
+	defer lock.Unlock()
+
+	content, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "*\n", string(content))
+}
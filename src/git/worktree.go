@@ -0,0 +1,84 @@
+// Package git checks out revisions of the repository the tool is run in
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is a temporary checkout of a revision, made with `git worktree`
+type Worktree struct {
+	// The root of the checkout
+	Dir string
+	// The revision that is checked out
+	Revision string
+
+	repo string
+}
+
+// AddWorktree checks out a revision of the repository dir is in to a
+// temporary directory. The worktree must be removed with Remove.
+func AddWorktree(dir string, revision string) (*Worktree, error) {
+	repo, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git repository of %s: %w", dir, err)
+	}
+
+	tmp, err := os.MkdirTemp("", "license-checker-worktree-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	if _, err := run(repo, "worktree", "add", "--detach", tmp, revision); err != nil {
+		os.RemoveAll(tmp)
+		return nil, fmt.Errorf("failed to check out %s: %w", revision, err)
+	}
+
+	return &Worktree{Dir: tmp, Revision: revision, repo: repo}, nil
+}
+
+// Path returns the path in the worktree matching a path in the repository's
+// main checkout
+func (w *Worktree) Path(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+	// the repository path is resolved by git, so symlinks have to be too
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+
+	rel, err := filepath.Rel(w.repo, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is not in the repository %s", path, w.repo)
+	}
+	return filepath.Join(w.Dir, rel), nil
+}
+
+// Remove deletes the worktree
+func (w *Worktree) Remove() error {
+	if _, err := run(w.repo, "worktree", "remove", "--force", w.Dir); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w", w.Dir, err)
+	}
+	return nil
+}
+
+// run runs git in a directory and returns its trimmed output
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
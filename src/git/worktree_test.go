@@ -0,0 +1,78 @@
+package git_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepo creates a repository with two commits, each with its own content
+// in sub/file.txt
+func newRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	gitCmd := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	gitCmd("init", "--quiet")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "sub"), 0755))
+	for _, content := range []string{"first", "second"} {
+		require.NoError(t, os.WriteFile(filepath.Join(repo, "sub", "file.txt"), []byte(content), 0644))
+		gitCmd("add", ".")
+		gitCmd("commit", "--quiet", "-m", content)
+	}
+
+	return repo
+}
+
+func TestAddWorktree(t *testing.T) {
+	repo := newRepo(t)
+
+	worktree, err := git.AddWorktree(filepath.Join(repo, "sub"), "HEAD~1")
+	require.NoError(t, err)
+
+	path, err := worktree.Path(filepath.Join(repo, "sub", "file.txt"))
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+
+	require.NoError(t, worktree.Remove())
+	assert.NoDirExists(t, worktree.Dir)
+}
+
+func TestAddWorktree_UnknownRevision(t *testing.T) {
+	repo := newRepo(t)
+
+	_, err := git.AddWorktree(repo, "no-such-revision")
+	assert.Error(t, err)
+}
+
+func TestWorktree_PathOutsideRepository(t *testing.T) {
+	repo := newRepo(t)
+
+	worktree, err := git.AddWorktree(repo, "HEAD")
+	require.NoError(t, err)
+	defer worktree.Remove()
+
+	_, err = worktree.Path(t.TempDir())
+	assert.Error(t, err)
+}
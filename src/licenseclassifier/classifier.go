@@ -0,0 +1,172 @@
+package licenseclassifier
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// The corpus of license texts that license files are compared to. The file
+// names are the SPDX IDs of the licenses.
+//
+//go:embed licenses/*.txt
+var corpus embed.FS
+
+// DefaultConfidenceThreshold is the confidence a match needs to have before
+// it's reasonable to say that a text is the license it matched.
+const DefaultConfidenceThreshold = 0.8
+
+// Match is the result of classifying a license text
+type Match struct {
+	// SPDX ID of the license most similar to the classified text
+	License string
+	// How similar the text is to the license, between 0 and 1
+	Confidence float64
+}
+
+// IsConfident returns true if the match is good enough to be trusted
+func (m Match) IsConfident() bool {
+	return m.License != "" && m.Confidence >= DefaultConfidenceThreshold
+}
+
+// Classifier identifies license texts by comparing them to a corpus of known
+// licenses.
+//
+// Usage:
+//
+//	classifier, err := licenseclassifier.New()
+//	if err != nil {
+//		...
+//	}
+//	match, err := classifier.ClassifyFile("vendor/some-dependency/LICENSE")
+//	if match.IsConfident() {
+//		fmt.Println(match.License) // MIT
+//	}
+type Classifier struct {
+	// SPDX ID -> bigrams of the normalized license text
+	templates map[string]bigrams
+	// sorted keys of `templates`, so that ties are broken the same way every time
+	licenses []string
+}
+
+// New creates a classifier using the embedded corpus of license texts
+func New() (*Classifier, error) {
+	entries, err := corpus.ReadDir("licenses")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read license corpus: %w", err)
+	}
+
+	c := &Classifier{
+		templates: make(map[string]bigrams),
+	}
+	for _, entry := range entries {
+		content, err := corpus.ReadFile(path.Join("licenses", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read license template %s: %w", entry.Name(), err)
+		}
+
+		license := strings.TrimSuffix(entry.Name(), ".txt")
+		c.templates[license] = newBigrams(normalize(string(content)))
+		c.licenses = append(c.licenses, license)
+	}
+	sort.Strings(c.licenses)
+
+	return c, nil
+}
+
+// Classify returns the license in the corpus that is most similar to the
+// given text. The returned match has an empty license if the text doesn't
+// resemble any license at all.
+func (c *Classifier) Classify(text string) Match {
+	textBigrams := newBigrams(normalize(text))
+
+	var best Match
+	for _, license := range c.licenses {
+		confidence := textBigrams.similarity(c.templates[license])
+		if confidence > best.Confidence {
+			best = Match{License: license, Confidence: confidence}
+		}
+	}
+
+	return best
+}
+
+// ClassifyFile reads a license file and classifies its contents
+func (c *Classifier) ClassifyFile(path string) (Match, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Match{}, fmt.Errorf("failed to read license file %s: %w", path, err)
+	}
+
+	return c.Classify(string(content)), nil
+}
+
+// bigrams counts each pair of consecutive words in a text. Comparing pairs
+// instead of single words makes the comparison sensitive to word order,
+// without caring about where in the text a sentence is.
+type bigrams struct {
+	counts map[string]int
+	total  int
+}
+
+func newBigrams(words []string) bigrams {
+	b := bigrams{counts: make(map[string]int)}
+	for i := 0; i+1 < len(words); i++ {
+		b.counts[words[i]+" "+words[i+1]]++
+		b.total++
+	}
+	return b
+}
+
+// similarity returns the Sørensen–Dice coefficient of the two sets of
+// bigrams; 1 if they're identical and 0 if they have nothing in common.
+func (b bigrams) similarity(other bigrams) float64 {
+	if b.total == 0 || other.total == 0 {
+		return 0
+	}
+
+	overlap := 0
+	for bigram, count := range b.counts {
+		overlap += min(count, other.counts[bigram])
+	}
+
+	return 2 * float64(overlap) / float64(b.total+other.total)
+}
+
+// LicenseText returns the text of a license in the corpus, without the
+// copyright line placeholders. The second return value is false if the
+// license isn't in the corpus.
+func LicenseText(license string) (string, bool) {
+	content, err := corpus.ReadFile(path.Join("licenses", license+".txt"))
+	if err != nil {
+		return "", false
+	}
+
+	text, _ := SplitCopyright(string(content))
+	return text, true
+}
+
+// SplitCopyright separates the copyright lines of a license text from the
+// rest of it, which is typically identical for every copy of the license
+func SplitCopyright(text string) (string, []string) {
+	var lines []string
+	var copyrights []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if isCopyrightLine(strings.ToLower(trimmed)) {
+			copyrights = append(copyrights, trimmed)
+			continue
+		}
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" && len(lines) > 0 && lines[len(lines)-1] == "" {
+			// the copyright lines often have blank lines on both sides
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), copyrights
+}
@@ -0,0 +1,174 @@
+package licenseclassifier_test
+
+import (
+	"strings"
+	"testing"
+
+	helpers_test "github.com/eriklarko/license-checker/src/helpers"
+	"github.com/eriklarko/license-checker/src/licenseclassifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mitWithDifferentFormatting = `The MIT License (MIT)
+
+Copyright (c) 2016-2024 Some Person <some.person@example.com>
+Copyright (c) 2015 Someone Else
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+`
+
+const bsd3WithBullets = `Copyright (c) 2009 The Go Authors. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+const isc = `Copyright (c) 2011-2024, Some Person
+
+Permission to use, copy, modify, and/or distribute this software for any
+purpose with or without fee is hereby granted, provided that the above
+copyright notice and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+`
+
+func TestClassify(t *testing.T) {
+	classifier, err := licenseclassifier.New()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		text            string
+		expectedLicense string
+	}{
+		"MIT with different copyright lines and whitespace": {
+			text:            mitWithDifferentFormatting,
+			expectedLicense: "MIT",
+		},
+		"BSD-3-Clause with bullets instead of numbers": {
+			text:            bsd3WithBullets,
+			expectedLicense: "BSD-3-Clause",
+		},
+		"ISC, which is almost identical to 0BSD": {
+			text:            isc,
+			expectedLicense: "ISC",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			match := classifier.Classify(tt.text)
+
+			assert.Equal(t, tt.expectedLicense, match.License)
+			assert.True(t, match.IsConfident(), "expected a confident match, got %f", match.Confidence)
+		})
+	}
+
+	t.Run("licenses in the same family", func(t *testing.T) {
+		for _, license := range []string{"GPL-2.0-only", "GPL-3.0-only", "LGPL-2.1-only", "LGPL-3.0-only", "AGPL-3.0-only", "MPL-2.0", "EPL-2.0", "CC0-1.0"} {
+			text, found := licenseclassifier.LicenseText(license)
+			require.True(t, found, license)
+
+			match := classifier.Classify(text)
+
+			assert.Equal(t, license, match.License)
+			assert.True(t, match.IsConfident(), "expected a confident match for %s, got %f", license, match.Confidence)
+		}
+	})
+
+	t.Run("text that is not a license", func(t *testing.T) {
+		match := classifier.Classify("This project is a collection of recipes for banana bread.")
+
+		assert.False(t, match.IsConfident(), "expected no confident match, got %s with %f", match.License, match.Confidence)
+	})
+
+	t.Run("empty text", func(t *testing.T) {
+		match := classifier.Classify("")
+
+		assert.Equal(t, licenseclassifier.Match{}, match)
+		assert.False(t, match.IsConfident())
+	})
+}
+
+func TestClassifyFile(t *testing.T) {
+	classifier, err := licenseclassifier.New()
+	require.NoError(t, err)
+
+	t.Run("existing file", func(t *testing.T) {
+		path := helpers_test.CreateTempFileWithContents(t, mitWithDifferentFormatting)
+
+		match, err := classifier.ClassifyFile(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, "MIT", match.License)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := classifier.ClassifyFile("does-not-exist")
+		assert.Error(t, err)
+	})
+}
+
+func TestLicenseText(t *testing.T) {
+	text, found := licenseclassifier.LicenseText("MIT")
+	require.True(t, found)
+	assert.True(t, strings.HasPrefix(text, "MIT License\n\nPermission is hereby granted"), text)
+
+	_, found = licenseclassifier.LicenseText("not-a-license")
+	assert.False(t, found)
+}
+
+func TestSplitCopyright(t *testing.T) {
+	text, copyrights := licenseclassifier.SplitCopyright(`The MIT License
+  Copyright (c) 2019 Some Person
+Copyright 2020 Another Person
+
+Permission is hereby granted...  
+`)
+
+	assert.Equal(t, "The MIT License\n\nPermission is hereby granted...", text)
+	assert.Equal(t, []string{"Copyright (c) 2019 Some Person", "Copyright 2020 Another Person"}, copyrights)
+
+	t.Run("lines starting with the word copyright in the license body", func(t *testing.T) {
+		for name, license := range map[string]string{"isc": isc, "bsd3WithBullets": bsd3WithBullets} {
+			text, copyrights := licenseclassifier.SplitCopyright(license)
+
+			lines := strings.Split(strings.TrimSpace(license), "\n")
+			assert.Equal(t, strings.Join(lines[2:], "\n"), text, name)
+			assert.Equal(t, []string{lines[0]}, copyrights, name)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package licenseclassifier
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// matches list markers at the start of a line, like `*`, `-`, `1.`, `(a)` or
+// `ii)`. Different copies of the same license love to disagree on these.
+var listMarkerRegex = regexp.MustCompile(`^(?:[-*•+]|\(?(?:[0-9]{1,2}|[a-z]|[ivx]{1,4})[.)])\s+`)
+
+// matches the start of copyright statements: `copyright (c)`, `copyright ©`,
+// `©`, `(c) 2024`, or `copyright` followed by a year or a year placeholder
+var copyrightRegex = regexp.MustCompile(`^(?:copyright:?\s*(?:\(c\)|©|[0-9]{4}|<year>|\[yyyy\])|©|\(c\)\s*[0-9]{4}|all rights reserved)`)
+
+// words that are spelled differently in otherwise identical license texts
+var equivalentWords = map[string]string{
+	"licence":  "license",
+	"licences": "licenses",
+	"https":    "http",
+	"&":        "and",
+}
+
+// normalize turns a license text into a list of words that can be compared to
+// other license texts without caring about formatting. It
+//   - removes copyright lines, as those differ between every copy of a license
+//   - removes list markers like bullets and clause numbers
+//   - lower-cases everything and removes punctuation
+//   - collapses all whitespace
+func normalize(text string) []string {
+	var words []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if isCopyrightLine(line) {
+			continue
+		}
+
+		line = listMarkerRegex.ReplaceAllString(line, "")
+		line = strings.ReplaceAll(line, "&", " & ")
+
+		for _, word := range strings.FieldsFunc(line, isSeparator) {
+			if equivalent, ok := equivalentWords[word]; ok {
+				word = equivalent
+			}
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// isCopyrightLine returns true if the lower-cased line is a copyright
+// statement, like `copyright (c) 2024 someone`. License texts also have lines
+// that merely start with the word, like `copyright notice and this permission
+// notice`, which are part of the license.
+func isCopyrightLine(line string) bool {
+	return copyrightRegex.MatchString(line)
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&'
+}
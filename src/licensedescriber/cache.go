@@ -0,0 +1,102 @@
+package licensedescriber
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CachedDescriber remembers the descriptions of another describer on disk,
+// so that slow or remote describers are only asked once per TTL. Empty
+// descriptions are cached too, so unknown licenses aren't asked for again and
+// again.
+type CachedDescriber struct {
+	describer Describer
+	dir       string
+	ttl       time.Duration
+
+	// overridden in tests
+	now func() time.Time
+}
+
+type cacheEntry struct {
+	License     string    `json:"license"`
+	Description string    `json:"description"`
+	FetchedAt   time.Time `json:"fetchedAt"`
+}
+
+func NewCachedDescriber(describer Describer, dir string, ttl time.Duration) *CachedDescriber {
+	return &CachedDescriber{
+		describer: describer,
+		dir:       dir,
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+func (c *CachedDescriber) Describe(license string) (string, error) {
+	path := c.path(license)
+	if entry, found := c.read(path); found && c.now().Sub(entry.FetchedAt) < c.ttl {
+		return entry.Description, nil
+	}
+
+	description, err := c.describer.Describe(license)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.write(path, cacheEntry{
+		License:     license,
+		Description: description,
+		FetchedAt:   c.now(),
+	})
+	if err != nil {
+		// the description is still good, it just has to be fetched again
+		slog.Warn("Failed to cache license description", "license", license, "error", err)
+	}
+
+	return description, nil
+}
+
+// path returns the path of the cache file of a license. The license is
+// hashed as license expressions can contain characters that aren't allowed in
+// file names.
+func (c *CachedDescriber) path(license string) string {
+	hash := sha256.Sum256([]byte(license))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".json")
+}
+
+func (c *CachedDescriber) read(path string) (cacheEntry, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		slog.Warn("Ignoring corrupt license description cache file", "path", path, "error", err)
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *CachedDescriber) write(path string, entry cacheEntry) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", c.dir, err)
+	}
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write cache file %s: %w", path, err)
+	}
+	return nil
+}
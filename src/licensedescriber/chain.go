@@ -0,0 +1,63 @@
+package licensedescriber
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/eriklarko/license-checker/src/boolexpr"
+)
+
+// Chain asks a list of describers, in order, for a description and returns
+// the first one that isn't empty. Put describers with company-specific
+// guidance first, and describers with generic summaries last.
+type Chain struct {
+	describers []Describer
+}
+
+func NewChain(describers ...Describer) *Chain {
+	return &Chain{describers: describers}
+}
+
+// Describe returns the first non-empty description. Describers that fail are
+// skipped, and their errors are only returned if no describer knew the
+// license.
+func (c *Chain) Describe(license string) (string, error) {
+	var errs []error
+	for _, describer := range c.describers {
+		description, err := describer.Describe(license)
+		if err != nil {
+			slog.Warn("License describer failed, trying the next one", "license", license, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		if description != "" {
+			return description, nil
+		}
+	}
+
+	return "", errors.Join(errs...)
+}
+
+// describeEach calls describe for every license in the expression and joins
+// the non-empty descriptions
+func describeEach(license string, describe func(license string) (string, error)) (string, error) {
+	node, err := boolexpr.New(license)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse license '%s': %w", license, err)
+	}
+
+	var descriptions []string
+	for _, variable := range node.Variables() {
+		description, err := describe(variable)
+		if err != nil {
+			return "", err
+		}
+		if description != "" {
+			descriptions = append(descriptions, description)
+		}
+	}
+
+	return strings.Join(descriptions, "\n\n"), nil
+}
@@ -0,0 +1,126 @@
+package licensedescriber_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	helpers_test "github.com/eriklarko/license-checker/src/helpers"
+	"github.com/eriklarko/license-checker/src/licensedescriber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticDescriber describes licenses using a map, and counts how often it's
+// asked
+type staticDescriber struct {
+	descriptions map[string]string
+	err          error
+	calls        int
+}
+
+func (s *staticDescriber) Describe(license string) (string, error) {
+	s.calls++
+	return s.descriptions[license], s.err
+}
+
+func TestChain(t *testing.T) {
+	company := &staticDescriber{descriptions: map[string]string{"GPL-3.0-only": "Ask legal first"}}
+	broken := &staticDescriber{err: errors.New("offline")}
+	generic := &staticDescriber{descriptions: map[string]string{"GPL-3.0-only": "A copyleft license", "MIT": "A permissive license"}}
+	chain := licensedescriber.NewChain(company, broken, generic)
+
+	t.Run("first describer wins", func(t *testing.T) {
+		description, err := chain.Describe("GPL-3.0-only")
+		require.NoError(t, err)
+		assert.Equal(t, "Ask legal first", description)
+	})
+
+	t.Run("falls back past failing describers", func(t *testing.T) {
+		description, err := chain.Describe("MIT")
+		require.NoError(t, err)
+		assert.Equal(t, "A permissive license", description)
+	})
+
+	t.Run("returns errors if no describer knows the license", func(t *testing.T) {
+		_, err := chain.Describe("ISC")
+		assert.ErrorContains(t, err, "offline")
+	})
+}
+
+func TestDirectoryDescriber(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "GPL-3.0-only.md"), []byte("# GPL-3.0-only\n\nOnly for internal tools.\n"), 0644))
+
+	describer := licensedescriber.NewDirectoryDescriber(dir)
+
+	description, err := describer.Describe("MIT || GPL-3.0-only")
+	require.NoError(t, err)
+	assert.Equal(t, "# GPL-3.0-only\n\nOnly for internal tools.", description)
+
+	description, err = describer.Describe("MIT")
+	require.NoError(t, err)
+	assert.Empty(t, description)
+}
+
+func TestHTTPDescriber(t *testing.T) {
+	server := helpers_test.NewMockServer()
+	defer server.Close()
+	server.AddStringResponse("/licenses/MIT", "Fine to use everywhere\n")
+
+	describer := licensedescriber.NewHTTPDescriber(server.URL() + "/licenses/")
+
+	description, err := describer.Describe("MIT")
+	require.NoError(t, err)
+	assert.Equal(t, "Fine to use everywhere", description)
+
+	// the mock server responds with 404 to unknown paths
+	description, err = describer.Describe("ISC")
+	require.NoError(t, err)
+	assert.Empty(t, description)
+}
+
+func TestCachedDescriber(t *testing.T) {
+	t.Run("uses cached descriptions within the TTL", func(t *testing.T) {
+		dir := t.TempDir()
+		inner := &staticDescriber{descriptions: map[string]string{"MIT": "A permissive license"}}
+
+		describer := licensedescriber.NewCachedDescriber(inner, dir, time.Hour)
+		for i := 0; i < 3; i++ {
+			description, err := describer.Describe("MIT")
+			require.NoError(t, err)
+			assert.Equal(t, "A permissive license", description)
+		}
+		assert.Equal(t, 1, inner.calls)
+
+		// the cache survives restarts
+		describer = licensedescriber.NewCachedDescriber(inner, dir, time.Hour)
+		_, err := describer.Describe("MIT")
+		require.NoError(t, err)
+		assert.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("expired descriptions are fetched again", func(t *testing.T) {
+		inner := &staticDescriber{descriptions: map[string]string{"MIT": "A permissive license"}}
+
+		describer := licensedescriber.NewCachedDescriber(inner, t.TempDir(), 0)
+		for i := 0; i < 2; i++ {
+			_, err := describer.Describe("MIT")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		inner := &staticDescriber{err: errors.New("offline")}
+
+		describer := licensedescriber.NewCachedDescriber(inner, t.TempDir(), time.Hour)
+		for i := 0; i < 2; i++ {
+			_, err := describer.Describe("MIT")
+			assert.Error(t, err)
+		}
+		assert.Equal(t, 2, inner.calls)
+	})
+}
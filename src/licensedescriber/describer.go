@@ -0,0 +1,15 @@
+package licensedescriber
+
+// Describer describes licenses to help users decide whether to allow them
+type Describer interface {
+	// Describe returns a human readable description of a license, or an
+	// empty string if the describer doesn't know the license
+	Describe(license string) (string, error)
+}
+
+var _ Describer = (*TLDRLegalLicenseDescriber)(nil)
+var _ Describer = (*EmbeddedDescriber)(nil)
+var _ Describer = (*DirectoryDescriber)(nil)
+var _ Describer = (*HTTPDescriber)(nil)
+var _ Describer = (*CachedDescriber)(nil)
+var _ Describer = (*Chain)(nil)
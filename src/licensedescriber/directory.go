@@ -0,0 +1,41 @@
+package licensedescriber
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirectoryDescriber reads license descriptions from a directory of Markdown
+// files named after the SPDX IDs of the licenses, e.g. `GPL-3.0-only.md`.
+// It's meant for guidance maintained by a legal team, like "allowed for
+// internal tools only".
+type DirectoryDescriber struct {
+	dir string
+}
+
+func NewDirectoryDescriber(dir string) *DirectoryDescriber {
+	return &DirectoryDescriber{dir: dir}
+}
+
+// Describe returns the contents of the files of every license in the
+// expression that has a file
+func (d *DirectoryDescriber) Describe(license string) (string, error) {
+	return describeEach(license, func(license string) (string, error) {
+		// SPDX IDs can't contain path separators, but don't trust the input
+		if strings.ContainsAny(license, `/\`) {
+			return "", nil
+		}
+
+		path := filepath.Join(d.dir, license+".md")
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return "", nil
+		} else if err != nil {
+			return "", fmt.Errorf("failed to read license description %s: %w", path, err)
+		}
+
+		return strings.TrimSpace(string(content)), nil
+	})
+}
@@ -0,0 +1,53 @@
+package licensedescriber
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPDescriber fetches license descriptions from an HTTP endpoint. For each
+// license it requests `<base URL>/<SPDX ID>` and expects the description as
+// plain text or Markdown in the response body. A 404 response means that the
+// endpoint doesn't know the license.
+type HTTPDescriber struct {
+	baseURL string
+
+	Client *http.Client
+}
+
+func NewHTTPDescriber(baseURL string) *HTTPDescriber {
+	return &HTTPDescriber{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (d *HTTPDescriber) Describe(license string) (string, error) {
+	return describeEach(license, d.describe)
+}
+
+func (d *HTTPDescriber) describe(license string) (string, error) {
+	resp, err := d.Client.Get(d.baseURL + "/" + url.PathEscape(license))
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch description of %s: %w", license, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch description of %s: %s", license, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read description of %s: %w", license, err)
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,139 @@
+package licensedescriber
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/eriklarko/license-checker/src/obligations"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed knowledgebase.yaml
+var embeddedKnowledgeBase []byte
+
+// LicenseInfo is what the knowledge base knows about a license
+type LicenseInfo struct {
+	ID          string
+	Name        string
+	OSIApproved bool
+	FSFLibre    bool
+	Summary     string
+	// Descriptions of what the license allows, requires and doesn't grant
+	Permissions []string
+	Conditions  []string
+	Limitations []string
+}
+
+type knowledgeBase struct {
+	Permissions map[string]string             `yaml:"permissions"`
+	Licenses    map[string]knowledgeBaseEntry `yaml:"licenses"`
+}
+
+type knowledgeBaseEntry struct {
+	Name        string   `yaml:"name"`
+	OSIApproved bool     `yaml:"osiApproved"`
+	FSFLibre    bool     `yaml:"fsfLibre"`
+	Permissions []string `yaml:"permissions"`
+	Summary     string   `yaml:"summary"`
+}
+
+// EmbeddedDescriber describes licenses using a knowledge base embedded in the
+// tool, so that it works without network access. The conditions and
+// limitations of each license come from the obligations catalogue.
+type EmbeddedDescriber struct {
+	licenses map[string]LicenseInfo
+}
+
+func NewEmbeddedDescriber() (*EmbeddedDescriber, error) {
+	var kb knowledgeBase
+	if err := yaml.Unmarshal(embeddedKnowledgeBase, &kb); err != nil {
+		return nil, fmt.Errorf("failed to parse license knowledge base: %w", err)
+	}
+
+	catalogue, err := obligations.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load obligations catalogue: %w", err)
+	}
+
+	d := &EmbeddedDescriber{licenses: make(map[string]LicenseInfo, len(kb.Licenses))}
+	for id, entry := range kb.Licenses {
+		info := LicenseInfo{
+			ID:          id,
+			Name:        entry.Name,
+			OSIApproved: entry.OSIApproved,
+			FSFLibre:    entry.FSFLibre,
+			Summary:     entry.Summary,
+		}
+
+		for _, permission := range entry.Permissions {
+			description, found := kb.Permissions[permission]
+			if !found {
+				return nil, fmt.Errorf("license %s refers to unknown permission '%s'", id, permission)
+			}
+			info.Permissions = append(info.Permissions, description)
+		}
+
+		terms, _ := catalogue.Terms(id)
+		for _, condition := range terms.Conditions {
+			info.Conditions = append(info.Conditions, catalogue.Conditions[condition])
+		}
+		for _, limitation := range terms.Limitations {
+			info.Limitations = append(info.Limitations, catalogue.Limitations[limitation])
+		}
+
+		d.licenses[id] = info
+	}
+
+	return d, nil
+}
+
+// Lookup returns what the knowledge base knows about a license. The second
+// return value is false if the license isn't in the knowledge base.
+func (d *EmbeddedDescriber) Lookup(license string) (LicenseInfo, bool) {
+	info, found := d.licenses[license]
+	return info, found
+}
+
+// Describe describes every license in the expression that is in the
+// knowledge base
+func (d *EmbeddedDescriber) Describe(license string) (string, error) {
+	return describeEach(license, func(license string) (string, error) {
+		if info, found := d.Lookup(license); found {
+			return info.describe(), nil
+		}
+		return "", nil
+	})
+}
+
+func (info LicenseInfo) describe() string {
+	var approvals []string
+	if info.OSIApproved {
+		approvals = append(approvals, "OSI approved")
+	}
+	if info.FSFLibre {
+		approvals = append(approvals, "FSF free/libre")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (%s)", info.Name, info.ID)
+	if len(approvals) > 0 {
+		fmt.Fprintf(&sb, ", %s", strings.Join(approvals, ", "))
+	}
+	fmt.Fprintf(&sb, "\n%s\n", info.Summary)
+
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\n%s:\n", title)
+		for _, item := range items {
+			fmt.Fprintf(&sb, "  - %s\n", item)
+		}
+	}
+	writeList("Permissions", info.Permissions)
+	writeList("Conditions", info.Conditions)
+	writeList("Limitations", info.Limitations)
+
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,82 @@
+package licensedescriber_test
+
+import (
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/licensedescriber"
+	"github.com/eriklarko/license-checker/src/obligations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedDescriber_Lookup(t *testing.T) {
+	describer, err := licensedescriber.NewEmbeddedDescriber()
+	require.NoError(t, err)
+
+	info, found := describer.Lookup("Apache-2.0")
+	require.True(t, found)
+
+	assert.Equal(t, "Apache License 2.0", info.Name)
+	assert.True(t, info.OSIApproved)
+	assert.True(t, info.FSFLibre)
+	assert.Contains(t, info.Permissions, "Patent grant from the contributors")
+	assert.Contains(t, info.Conditions, "Propagate the contents of the NOTICE file, if there is one, in distributions of the software")
+	assert.NotEmpty(t, info.Limitations)
+	assert.NotEmpty(t, info.Summary)
+
+	_, found = describer.Lookup("LicenseRef-Bespoke")
+	assert.False(t, found)
+}
+
+func TestEmbeddedDescriber_Describe(t *testing.T) {
+	describer, err := licensedescriber.NewEmbeddedDescriber()
+	require.NoError(t, err)
+
+	t.Run("single license", func(t *testing.T) {
+		description, err := describer.Describe("MIT")
+		require.NoError(t, err)
+
+		assert.Equal(t, `MIT License (MIT), OSI approved, FSF free/libre
+A short, permissive license. You can do almost anything with the software as long as the copyright notice and license text are kept.
+
+Permissions:
+  - Commercial use
+  - Modification
+  - Distribution
+  - Private use
+
+Conditions:
+  - Include the copyright notice and license text in copies of the software, including binary distributions
+
+Limitations:
+  - The license includes a limitation of liability
+  - The license explicitly states that it does not provide any warranty`, description)
+	})
+
+	t.Run("expression describes every known license", func(t *testing.T) {
+		description, err := describer.Describe("MIT || LicenseRef-Bespoke || GPL-3.0-only")
+		require.NoError(t, err)
+
+		assert.Contains(t, description, "MIT License (MIT)")
+		assert.Contains(t, description, "GNU General Public License v3.0 only (GPL-3.0-only)")
+		assert.NotContains(t, description, "LicenseRef-Bespoke")
+	})
+
+	t.Run("unknown license", func(t *testing.T) {
+		description, err := describer.Describe("LicenseRef-Bespoke")
+		require.NoError(t, err)
+		assert.Empty(t, description)
+	})
+}
+
+func TestEmbeddedDescriber_MatchesObligationsCatalogue(t *testing.T) {
+	describer, err := licensedescriber.NewEmbeddedDescriber()
+	require.NoError(t, err)
+	catalogue, err := obligations.Load()
+	require.NoError(t, err)
+
+	for license := range catalogue.Licenses {
+		_, found := describer.Lookup(license)
+		assert.True(t, found, "%s is in the obligations catalogue but not in the knowledge base", license)
+	}
+}
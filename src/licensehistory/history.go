@@ -0,0 +1,128 @@
+// Package licensehistory remembers the version and license each dependency
+// had the last time it was checked, to detect dependencies that change their
+// license in an upgrade
+package licensehistory
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/eriklarko/license-checker/src/atomicfile"
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+)
+
+// the first row of the csv file
+var header = []string{"dependency", "version", "license"}
+
+// Entry is the last seen version and license of a dependency
+type Entry struct {
+	Version string
+	License string
+}
+
+// History is stored as a csv file with the columns
+// `dependency,version,license`. Dependencies that are no longer used are
+// kept, so that a dependency that comes back with another license is
+// detected too.
+type History struct {
+	path    string
+	entries map[string]Entry
+}
+
+// Load reads the history from a csv file. A missing file is an empty
+// history.
+func Load(path string) (*History, error) {
+	h := &History{path: path, entries: make(map[string]Entry)}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = len(header)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read records from %s: %w", path, err)
+	}
+
+	for i, record := range records {
+		if i == 0 {
+			// header
+			continue
+		}
+		h.entries[record[0]] = Entry{Version: record[1], License: record[2]}
+	}
+
+	return h, nil
+}
+
+// Get returns the last seen version and license of a dependency
+func (h *History) Get(dependency string) (Entry, bool) {
+	entry, found := h.entries[dependency]
+	return entry, found
+}
+
+// Compare returns the dependencies whose license differs from the last seen
+// one. Dependencies that haven't been seen before are not relicensed.
+func (h *History) Compare(dependencies []collector.Dependency) []checker.Relicensing {
+	var relicensings []checker.Relicensing
+	for _, dependency := range dependencies {
+		previous, found := h.entries[dependency.Name]
+		license := collector.LicenseExpression(dependency)
+		if !found || previous.License == license {
+			continue
+		}
+
+		relicensings = append(relicensings, checker.Relicensing{
+			Dependency:      dependency.Name,
+			PreviousVersion: previous.Version,
+			PreviousLicense: previous.License,
+			Version:         dependency.Version,
+			License:         license,
+		})
+	}
+
+	sort.Slice(relicensings, func(i, j int) bool {
+		return relicensings[i].Dependency < relicensings[j].Dependency
+	})
+	return relicensings
+}
+
+// Update remembers the current version and license of the dependencies
+func (h *History) Update(dependencies []collector.Dependency) {
+	for _, dependency := range dependencies {
+		h.entries[dependency.Name] = Entry{
+			Version: dependency.Version,
+			License: collector.LicenseExpression(dependency),
+		}
+	}
+}
+
+// Save writes the history to its csv file, sorted by dependency
+func (h *History) Save() error {
+	dependencies := make([]string, 0, len(h.entries))
+	for dependency := range h.entries {
+		dependencies = append(dependencies, dependency)
+	}
+	sort.Strings(dependencies)
+
+	return atomicfile.WriteFile(h.path, 0644, func(w io.Writer) error {
+		records := [][]string{header}
+		for _, dependency := range dependencies {
+			entry := h.entries[dependency]
+			records = append(records, []string{dependency, entry.Version, entry.License})
+		}
+		if err := csv.NewWriter(w).WriteAll(records); err != nil {
+			return fmt.Errorf("failed to write records to CSV: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,50 @@
+package licensehistory_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+	"github.com/eriklarko/license-checker/src/licensehistory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".license-checker", "license-history.csv")
+
+	history, err := licensehistory.Load(path)
+	require.NoError(t, err)
+
+	// nothing has been seen yet
+	assert.Empty(t, history.Compare([]collector.Dependency{{Name: "some-lib", Version: "1.0.0", License: "MIT"}}))
+
+	history.Update([]collector.Dependency{
+		{Name: "some-lib", Version: "1.0.0", License: "MIT"},
+		{Name: "removed-lib", Version: "0.1.0", License: "ISC"},
+	})
+	require.NoError(t, history.Save())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "dependency,version,license\nremoved-lib,0.1.0,ISC\nsome-lib,1.0.0,MIT\n", string(content))
+
+	history, err = licensehistory.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []checker.Relicensing{
+		{Dependency: "some-lib", PreviousVersion: "1.0.0", PreviousLicense: "MIT", Version: "2.0.0", License: "BUSL-1.1"},
+	}, history.Compare([]collector.Dependency{
+		{Name: "some-lib", Version: "2.0.0", License: "BUSL-1.1"},
+		{Name: "new-lib", Version: "1.0.0", License: "MIT"},
+	}))
+
+	// upgrades without license changes aren't relicensings
+	assert.Empty(t, history.Compare([]collector.Dependency{{Name: "some-lib", Version: "1.1.0", License: "MIT"}}))
+
+	// dependencies that aren't used any more are remembered
+	entry, found := history.Get("removed-lib")
+	assert.True(t, found)
+	assert.Equal(t, licensehistory.Entry{Version: "0.1.0", License: "ISC"}, entry)
+}
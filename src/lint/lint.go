@@ -0,0 +1,452 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eriklarko/license-checker/src/boolexpr"
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Check is a kind of problem the linter looks for
+type Check string
+
+const (
+	// the file can't be parsed
+	InvalidYAML Check = "invalid-yaml"
+	// the file is valid yaml, but not in the expected shape, e.g. a misspelled
+	// setting or a decision that isn't true or false
+	Schema Check = "schema"
+	// a decision is made on a license expression instead of a single license
+	ExpressionKey Check = "expression-key"
+	// a decision is made on a license that isn't in the SPDX License List
+	UnknownLicense Check = "unknown-license"
+	// a decision is made on a deprecated SPDX ID
+	DeprecatedLicense Check = "deprecated-license"
+	// the same license or setting is there twice, possibly with different
+	// casing
+	DuplicateKey Check = "duplicate-key"
+	// a license is allowed for all dependencies but denied for a single
+	// dependency, or the other way around, without a comment explaining why
+	ConflictingDecision Check = "conflicting-decision"
+	// a decision that doesn't apply to any of the current dependencies
+	StaleDecision Check = "stale-decision"
+)
+
+// AllChecks lists every check the linter does
+var AllChecks = []Check{
+	InvalidYAML,
+	Schema,
+	ExpressionKey,
+	UnknownLicense,
+	DeprecatedLicense,
+	DuplicateKey,
+	ConflictingDecision,
+	StaleDecision,
+}
+
+// Finding is a problem found on a line of a file
+type Finding struct {
+	Path string
+	// 1-based line number, 0 if the line isn't known
+	Line    int
+	Check   Check
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", f.Path, f.Line, f.Message, f.Check)
+}
+
+// Linter finds problems in the licenses file and the config
+type Linter struct {
+	// the license expressions of the current dependencies, keyed by
+	// dependency. Stale decisions are only looked for if this is set.
+	Dependencies map[string]string
+	// checks whose findings aren't reported
+	Disabled []Check
+
+	spdx *spdxList
+}
+
+func New() (*Linter, error) {
+	spdx, err := loadSPDXList()
+	if err != nil {
+		return nil, err
+	}
+	return &Linter{spdx: spdx}, nil
+}
+
+// decision is a license allowed or denied in the licenses file
+type decision struct {
+	license string
+	allowed bool
+	line    int
+	// true if there is a comment above the decision
+	hasReason bool
+}
+
+// LicensesFile returns the problems found in the licenses file, sorted by
+// line
+func (l *Linter) LicensesFile(path string, data []byte) []Finding {
+	r := &run{linter: l, path: path}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		r.addYAMLError(InvalidYAML, err)
+		return r.sortedFindings()
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		r.add(root.Line, Schema, "expected a map of licenses to true or false")
+		return r.sortedFindings()
+	}
+
+	var dependencies *yaml.Node
+	decisions := r.decisions(root, func(key, value *yaml.Node) bool {
+		if key.Value != checker.DependenciesKey {
+			return false
+		}
+		if value.Kind != yaml.MappingNode {
+			r.add(value.Line, Schema, "expected %s to be a map of dependencies to their decisions", checker.DependenciesKey)
+		} else {
+			dependencies = value
+		}
+		return true
+	})
+	if l.Dependencies != nil {
+		r.staleDecisions(decisions)
+	}
+	if dependencies != nil {
+		r.dependencyDecisions(dependencies, decisions)
+	}
+
+	return r.sortedFindings()
+}
+
+// Config returns the problems found in the config file, sorted by line
+func (l *Linter) Config(path string, data []byte) []Finding {
+	r := &run{linter: l, path: path}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		r.addYAMLError(InvalidYAML, err)
+		return r.sortedFindings()
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		r.add(root.Line, Schema, "expected a map of settings")
+		return r.sortedFindings()
+	}
+
+	settings := configSettings()
+	lines := make(map[string]int)
+	for i := 0; i < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if line, ok := lines[key.Value]; ok {
+			r.add(key.Line, DuplicateKey, "%s is set on line %d as well", key.Value, line)
+			continue
+		}
+		lines[key.Value] = key.Line
+
+		if !slices.Contains(settings, key.Value) {
+			message := fmt.Sprintf("unknown setting %s", key.Value)
+			if suggestion := closest(key.Value, settings); suggestion != "" {
+				message += fmt.Sprintf(", did you mean %s?", suggestion)
+			}
+			r.add(key.Line, Schema, "%s", message)
+		}
+	}
+
+	var conf config.Config
+	if err := root.Decode(&conf); err != nil {
+		r.addYAMLError(Schema, err)
+	}
+
+	return r.sortedFindings()
+}
+
+// run collects the findings in a single file
+type run struct {
+	linter   *Linter
+	path     string
+	findings []Finding
+}
+
+func (r *run) add(line int, check Check, format string, args ...any) {
+	if slices.Contains(r.linter.Disabled, check) {
+		return
+	}
+
+	r.findings = append(r.findings, Finding{
+		Path:    r.path,
+		Line:    line,
+		Check:   check,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// yamlErrorRegex matches the line number in the errors returned by the yaml
+// library, e.g. `yaml: line 3: did not find expected key`
+var yamlErrorRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+func (r *run) addYAMLError(check Check, err error) {
+	messages := []string{err.Error()}
+	var typeError *yaml.TypeError
+	if errors.As(err, &typeError) {
+		messages = typeError.Errors
+	}
+
+	for _, message := range messages {
+		if strings.Contains(message, "already defined at line") {
+			// duplicate keys are reported on their own
+			continue
+		}
+
+		line := 0
+		if match := yamlErrorRegex.FindStringSubmatch(message); match != nil {
+			line, _ = strconv.Atoi(match[1])
+			message = match[2]
+		}
+		r.add(line, check, "%s", message)
+	}
+}
+
+// decisions lints the decisions in a map of licenses to true or false. Keys
+// the skip function returns true for are left to the caller.
+func (r *run) decisions(node *yaml.Node, skip func(key, value *yaml.Node) bool) []decision {
+	var decisions []decision
+	seen := make(map[string]decision)
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if skip != nil && skip(key, value) {
+			continue
+		}
+
+		if value.Kind == yaml.MappingNode && closest(key.Value, []string{checker.DependenciesKey}) != "" {
+			r.add(key.Line, Schema, "unknown section %s, did you mean %s?", key.Value, checker.DependenciesKey)
+			continue
+		}
+		r.license(key)
+
+		var allowed bool
+		if value.Kind != yaml.ScalarNode || value.Decode(&allowed) != nil {
+			r.add(value.Line, Schema, "expected the decision for %s to be true or false, but got '%s'", key.Value, value.Value)
+			continue
+		}
+
+		d := decision{
+			license:   key.Value,
+			allowed:   allowed,
+			line:      key.Line,
+			hasReason: key.HeadComment != "",
+		}
+		normalized := normalize(key.Value)
+		if previous, ok := seen[normalized]; ok {
+			r.duplicate(previous, d)
+			continue
+		}
+		seen[normalized] = d
+		decisions = append(decisions, d)
+	}
+	return decisions
+}
+
+func (r *run) duplicate(previous, d decision) {
+	message := fmt.Sprintf("%s is decided on line %d as well", d.license, previous.line)
+	if previous.license != d.license {
+		message = fmt.Sprintf("%s is the same license as %s on line %d", d.license, previous.license, previous.line)
+	}
+	if previous.allowed != d.allowed {
+		message += fmt.Sprintf(", and is both %s and %s", verb(previous.allowed), verb(d.allowed))
+	}
+	r.add(d.line, DuplicateKey, "%s", message)
+}
+
+// license lints the license a decision is made on
+func (r *run) license(key *yaml.Node) {
+	license := key.Value
+	spdx := r.linter.spdx
+
+	switch {
+	case !checker.IsSingleLicense(license):
+		r.add(key.Line, ExpressionKey, "%s is a license expression, decisions are made on the single licenses in it", strconv.Quote(license))
+	case spdx.isDeprecated(license):
+		message := fmt.Sprintf("%s is a deprecated SPDX ID", license)
+		if replacements := spdx.replacements(license); len(replacements) > 0 {
+			message += fmt.Sprintf(", use %s instead", strings.Join(replacements, " or "))
+		}
+		r.add(key.Line, DeprecatedLicense, "%s", message)
+	case !spdx.isKnown(license):
+		message := fmt.Sprintf("%s is not an SPDX ID", license)
+		if id := spdx.correctCase(license); id != "" {
+			message += fmt.Sprintf(", did you mean %s?", id)
+		}
+		r.add(key.Line, UnknownLicense, "%s", message)
+	}
+}
+
+// staleDecisions reports the decisions on licenses no current dependency is
+// licensed under
+func (r *run) staleDecisions(decisions []decision) {
+	used := make(map[string]bool)
+	for _, expression := range r.linter.Dependencies {
+		for _, license := range licensesIn(expression) {
+			used[license] = true
+		}
+	}
+
+	for _, d := range decisions {
+		if !used[d.license] {
+			r.add(d.line, StaleDecision, "no dependency is licensed under %s", d.license)
+		}
+	}
+}
+
+// dependencyDecisions lints the decisions that only apply to single
+// dependencies
+func (r *run) dependencyDecisions(node *yaml.Node, globalDecisions []decision) {
+	global := make(map[string]decision)
+	for _, d := range globalDecisions {
+		global[d.license] = d
+	}
+
+	lines := make(map[string]int)
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		dependency := key.Value
+		if line, ok := lines[dependency]; ok {
+			r.add(key.Line, DuplicateKey, "%s has decisions on line %d as well", dependency, line)
+		}
+		lines[dependency] = key.Line
+
+		if value.Kind != yaml.MappingNode {
+			r.add(value.Line, Schema, "expected the decisions for %s to be a map of licenses to true or false", dependency)
+			continue
+		}
+
+		decisions := r.decisions(value, nil)
+		for _, d := range decisions {
+			if g, ok := global[d.license]; ok && g.allowed != d.allowed && !d.hasReason {
+				r.add(d.line, ConflictingDecision,
+					"%s is %s for all dependencies on line %d but %s for %s, add a comment above it explaining why",
+					d.license, verb(g.allowed), g.line, verb(d.allowed), dependency,
+				)
+			}
+		}
+
+		if r.linter.Dependencies == nil {
+			continue
+		}
+		expression, ok := r.linter.Dependencies[dependency]
+		if !ok {
+			r.add(key.Line, StaleDecision, "%s is not a dependency", dependency)
+			continue
+		}
+		for _, d := range decisions {
+			if !slices.Contains(licensesIn(expression), d.license) {
+				r.add(d.line, StaleDecision, "%s is not licensed under %s", dependency, d.license)
+			}
+		}
+	}
+}
+
+func (r *run) sortedFindings() []Finding {
+	sort.SliceStable(r.findings, func(i, j int) bool {
+		return r.findings[i].Line < r.findings[j].Line
+	})
+	return r.findings
+}
+
+// licensesIn returns the licenses in a license expression
+func licensesIn(expression string) []string {
+	if checker.IsSingleLicense(expression) {
+		return []string{expression}
+	}
+
+	node, err := boolexpr.New(expression)
+	if err != nil {
+		return []string{expression}
+	}
+	return node.Variables()
+}
+
+// normalize returns the form of a license two spellings of the same license
+// have in common
+func normalize(license string) string {
+	return strings.ToLower(strings.TrimSpace(license))
+}
+
+func verb(allowed bool) string {
+	if allowed {
+		return "allowed"
+	}
+	return "denied"
+}
+
+// configSettings returns the names of the settings in the config file
+func configSettings() []string {
+	var settings []string
+	t := reflect.TypeOf(config.Config{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			settings = append(settings, name)
+		}
+	}
+	return settings
+}
+
+// closest returns the candidate that is a likely misspelling of s, or an
+// empty string if none is
+func closest(s string, candidates []string) string {
+	best := ""
+	bestDistance := 0
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(s), candidate)
+		maxDistance := max(1, len(candidate)/4)
+		if distance <= maxDistance && (best == "" || distance < bestDistance) {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,211 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLinter(t *testing.T) *lint.Linter {
+	linter, err := lint.New()
+	require.NoError(t, err)
+	return linter
+}
+
+func TestLicensesFile(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		expected []lint.Finding
+	}{
+		"no problems": {
+			content: `
+MIT: true
+GPL-3.0-only: false
+LicenseRef-Custom: true
+NOASSERTION: false
+dependencies:
+    some-dep:
+        # approved by legal
+        GPL-3.0-only: true
+`,
+			expected: nil,
+		},
+		"empty file": {
+			content:  "",
+			expected: nil,
+		},
+		"invalid yaml": {
+			content: "MIT: true\n  GPL: [false\n",
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 2, Check: lint.InvalidYAML, Message: "mapping values are not allowed in this context"},
+			},
+		},
+		"not a map": {
+			content: "- MIT\n",
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 1, Check: lint.Schema, Message: "expected a map of licenses to true or false"},
+			},
+		},
+		"decisions that aren't true or false": {
+			content: "MIT: ture\nISC: [true]\n",
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 1, Check: lint.Schema, Message: "expected the decision for MIT to be true or false, but got 'ture'"},
+				{Path: "licenses.yaml", Line: 2, Check: lint.Schema, Message: "expected the decision for ISC to be true or false, but got ''"},
+			},
+		},
+		"misspelled dependencies section": {
+			content: "MIT: true\ndependecies:\n    some-dep:\n        ISC: true\n",
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 2, Check: lint.Schema, Message: "unknown section dependecies, did you mean dependencies?"},
+			},
+		},
+		"expressions as keys": {
+			content: "MIT || ISC: true\nApache 2.0: true\n",
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 1, Check: lint.ExpressionKey, Message: `"MIT || ISC" is a license expression, decisions are made on the single licenses in it`},
+				{Path: "licenses.yaml", Line: 2, Check: lint.ExpressionKey, Message: `"Apache 2.0" is a license expression, decisions are made on the single licenses in it`},
+			},
+		},
+		"unknown and deprecated SPDX IDs": {
+			content: "Mit: true\nSome-License: false\nGPL-3.0: false\nGPL-2.0+: false\n",
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 1, Check: lint.UnknownLicense, Message: "Mit is not an SPDX ID, did you mean MIT?"},
+				{Path: "licenses.yaml", Line: 2, Check: lint.UnknownLicense, Message: "Some-License is not an SPDX ID"},
+				{Path: "licenses.yaml", Line: 3, Check: lint.DeprecatedLicense, Message: "GPL-3.0 is a deprecated SPDX ID, use GPL-3.0-only or GPL-3.0-or-later instead"},
+				{Path: "licenses.yaml", Line: 4, Check: lint.DeprecatedLicense, Message: "GPL-2.0+ is a deprecated SPDX ID, use GPL-2.0-or-later instead"},
+			},
+		},
+		"licenses with exceptions": {
+			content: "GPL-2.0-only-WITH-Classpath-exception-2.0: true\nGPL-2.0-only-WITH-Made-up-exception: false\n",
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 2, Check: lint.UnknownLicense, Message: "GPL-2.0-only-WITH-Made-up-exception is not an SPDX ID"},
+			},
+		},
+		"duplicate keys": {
+			content: "MIT: true\nISC: true\nMIT: false\nisc: true\n",
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 3, Check: lint.DuplicateKey, Message: "MIT is decided on line 1 as well, and is both allowed and denied"},
+				{Path: "licenses.yaml", Line: 4, Check: lint.UnknownLicense, Message: "isc is not an SPDX ID, did you mean ISC?"},
+				{Path: "licenses.yaml", Line: 4, Check: lint.DuplicateKey, Message: "isc is the same license as ISC on line 2"},
+			},
+		},
+		"conflicting decisions without a reason": {
+			content: `
+MIT: true
+GPL-3.0-only: false
+dependencies:
+    some-dep:
+        MIT: false
+    other-dep:
+        # approved by legal
+        GPL-3.0-only: true
+    some-dep:
+        ISC: true
+`,
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 6, Check: lint.ConflictingDecision, Message: "MIT is allowed for all dependencies on line 2 but denied for some-dep, add a comment above it explaining why"},
+				{Path: "licenses.yaml", Line: 10, Check: lint.DuplicateKey, Message: "some-dep has decisions on line 5 as well"},
+			},
+		},
+		"dependencies section that isn't a map": {
+			content: "MIT: true\ndependencies: true\n",
+			expected: []lint.Finding{
+				{Path: "licenses.yaml", Line: 2, Check: lint.Schema, Message: "expected dependencies to be a map of dependencies to their decisions"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			findings := newLinter(t).LicensesFile("licenses.yaml", []byte(test.content))
+			assert.Equal(t, test.expected, findings)
+		})
+	}
+}
+
+func TestLicensesFile_StaleDecisions(t *testing.T) {
+	content := `
+MIT: true
+ISC: true
+GPL-3.0-only: false
+dependencies:
+    dep-1:
+        # approved by legal
+        GPL-3.0-only: true
+        Apache-2.0: true
+    removed-dep:
+        MIT: true
+`
+	sut := newLinter(t)
+	sut.Dependencies = map[string]string{
+		"dep-1": "GPL-3.0-only || MIT",
+		"dep-2": "MIT",
+	}
+
+	findings := sut.LicensesFile("licenses.yaml", []byte(content))
+
+	assert.Equal(t, []lint.Finding{
+		{Path: "licenses.yaml", Line: 3, Check: lint.StaleDecision, Message: "no dependency is licensed under ISC"},
+		{Path: "licenses.yaml", Line: 9, Check: lint.StaleDecision, Message: "dep-1 is not licensed under Apache-2.0"},
+		{Path: "licenses.yaml", Line: 10, Check: lint.StaleDecision, Message: "removed-dep is not a dependency"},
+	}, findings)
+}
+
+func TestLicensesFile_DisabledChecks(t *testing.T) {
+	sut := newLinter(t)
+	sut.Dependencies = map[string]string{}
+	sut.Disabled = []lint.Check{lint.StaleDecision, lint.UnknownLicense}
+
+	findings := sut.LicensesFile("licenses.yaml", []byte("Some-License: true\nGPL-3.0: false\n"))
+
+	assert.Equal(t, []lint.Finding{
+		{Path: "licenses.yaml", Line: 2, Check: lint.DeprecatedLicense, Message: "GPL-3.0 is a deprecated SPDX ID, use GPL-3.0-only or GPL-3.0-or-later instead"},
+	}, findings)
+}
+
+func TestConfig(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		expected []lint.Finding
+	}{
+		"no problems": {
+			content:  "licenses-file: licenses.yaml\nrecursive: true\nignore:\n  - vendor\n",
+			expected: nil,
+		},
+		"misspelled settings": {
+			content: "licences-file: licenses.yaml\nrecursiv: true\nsomething-else: 1\n",
+			expected: []lint.Finding{
+				{Path: "config.yaml", Line: 1, Check: lint.Schema, Message: "unknown setting licences-file, did you mean licenses-file?"},
+				{Path: "config.yaml", Line: 2, Check: lint.Schema, Message: "unknown setting recursiv, did you mean recursive?"},
+				{Path: "config.yaml", Line: 3, Check: lint.Schema, Message: "unknown setting something-else"},
+			},
+		},
+		"values of the wrong type": {
+			content: "licenses-file: licenses.yaml\nrecursive: sometimes\n",
+			expected: []lint.Finding{
+				{Path: "config.yaml", Line: 2, Check: lint.Schema, Message: "cannot unmarshal !!str `sometimes` into bool"},
+			},
+		},
+		"duplicate settings": {
+			content: "licenses-file: licenses.yaml\nlicenses-file: other.yaml\n",
+			expected: []lint.Finding{
+				{Path: "config.yaml", Line: 2, Check: lint.DuplicateKey, Message: "licenses-file is set on line 1 as well"},
+			},
+		},
+		"invalid yaml": {
+			content: "licenses-file: [\n",
+			expected: []lint.Finding{
+				{Path: "config.yaml", Line: 1, Check: lint.InvalidYAML, Message: "did not find expected node content"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			findings := newLinter(t).Config("config.yaml", []byte(test.content))
+			assert.Equal(t, test.expected, findings)
+		})
+	}
+}
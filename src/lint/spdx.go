@@ -0,0 +1,95 @@
+package lint
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/eriklarko/license-checker/src/collector"
+	"github.com/eriklarko/license-checker/src/sbom"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed spdx.yaml
+var embeddedSPDXList []byte
+
+// spdxList is the SPDX License List, used to find license IDs that are
+// misspelled or deprecated
+type spdxList struct {
+	Licenses   []string `yaml:"licenses"`
+	Deprecated []string `yaml:"deprecated"`
+	Exceptions []string `yaml:"exceptions"`
+
+	// all IDs, keyed by their lowercase form
+	byLowercase map[string]string
+	deprecated  map[string]bool
+	exceptions  map[string]bool
+}
+
+func loadSPDXList() (*spdxList, error) {
+	list := &spdxList{}
+	if err := yaml.Unmarshal(embeddedSPDXList, list); err != nil {
+		return nil, fmt.Errorf("failed to parse SPDX license list: %w", err)
+	}
+
+	list.byLowercase = make(map[string]string)
+	list.deprecated = make(map[string]bool)
+	list.exceptions = make(map[string]bool)
+	for _, id := range list.Licenses {
+		list.byLowercase[strings.ToLower(id)] = id
+	}
+	for _, id := range list.Deprecated {
+		list.byLowercase[strings.ToLower(id)] = id
+		list.deprecated[id] = true
+	}
+	for _, id := range list.Exceptions {
+		list.exceptions[id] = true
+	}
+	return list, nil
+}
+
+// isKnown returns true if the license is an SPDX ID, or one of the things
+// SPDX allows in place of an ID. Licenses with an exception are known if both
+// the license and the exception are, like the
+// `GPL-2.0-only-WITH-Classpath-exception-2.0` the SBOM collector turns
+// `GPL-2.0-only WITH Classpath-exception-2.0` into.
+func (l *spdxList) isKnown(license string) bool {
+	if license == collector.NoAssertion ||
+		strings.HasPrefix(license, "LicenseRef-") ||
+		strings.HasPrefix(license, "DocumentRef-") {
+		return true
+	}
+	if id, exception, found := strings.Cut(license, sbom.WithSeparator); found {
+		return l.isKnown(id) && l.exceptions[exception]
+	}
+	return l.byLowercase[strings.ToLower(license)] == license
+}
+
+// correctCase returns the SPDX ID the license is a differently cased version
+// of, or an empty string
+func (l *spdxList) correctCase(license string) string {
+	return l.byLowercase[strings.ToLower(license)]
+}
+
+func (l *spdxList) isDeprecated(license string) bool {
+	return l.deprecated[license]
+}
+
+// replacements returns the IDs that replaced a deprecated ID, if they can be
+// derived from it, e.g. GPL-3.0-only and GPL-3.0-or-later for GPL-3.0
+func (l *spdxList) replacements(license string) []string {
+	if base, ok := strings.CutSuffix(license, "+"); ok {
+		if l.isKnown(base + "-or-later") {
+			return []string{base + "-or-later"}
+		}
+		return nil
+	}
+
+	var replacements []string
+	for _, suffix := range []string{"-only", "-or-later"} {
+		if l.isKnown(license + suffix) {
+			replacements = append(replacements, license+suffix)
+		}
+	}
+	return replacements
+}
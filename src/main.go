@@ -1,22 +1,39 @@
 package main
 
 import (
-	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/eriklarko/license-checker/src/atomicfile"
+	"github.com/eriklarko/license-checker/src/baseline"
 	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
 	"github.com/eriklarko/license-checker/src/config"
 	"github.com/eriklarko/license-checker/src/curatedlicensescripts"
 	"github.com/eriklarko/license-checker/src/curatedlicensescripts/packagemanagerdetector"
 	"github.com/eriklarko/license-checker/src/curatedlists"
+	"github.com/eriklarko/license-checker/src/depdiff"
 	"github.com/eriklarko/license-checker/src/environment"
+	"github.com/eriklarko/license-checker/src/filelock"
+	"github.com/eriklarko/license-checker/src/git"
+	"github.com/eriklarko/license-checker/src/licenseclassifier"
 	"github.com/eriklarko/license-checker/src/licensedescriber"
+	"github.com/eriklarko/license-checker/src/licensehistory"
+	"github.com/eriklarko/license-checker/src/lint"
+	"github.com/eriklarko/license-checker/src/notices"
+	"github.com/eriklarko/license-checker/src/obligations"
+	"github.com/eriklarko/license-checker/src/pending"
 	"github.com/eriklarko/license-checker/src/phraser"
+	"github.com/eriklarko/license-checker/src/referencedlicense"
+	"github.com/eriklarko/license-checker/src/reporter"
+	"github.com/eriklarko/license-checker/src/sbom"
 	"github.com/eriklarko/license-checker/src/tui"
 	"github.com/samber/lo"
 )
@@ -27,11 +44,33 @@ var configFilePath = flag.String("config-file", defaultConfigFilePath, "Path to
 var licensesScript = flag.String("licenses-script", "", "Path to the script for getting current licenses")
 var licensesFile = flag.String("licenses-file", "", "Path to the file containing approved and disapproved licenses")
 var interactive = flag.Bool("interactive", false, "Force the script into interactive mode")
+var goBinary = flag.String("go-binary", "", "Path to a compiled Go binary. Checks the modules embedded in it instead of running the licenses script")
+var vendorDir = flag.String("vendor-dir", "", "Directory with vendored Go modules, searched for license files of the modules in --go-binary")
+var sbomFile = flag.String("sbom", "", "Path to an SPDX or CycloneDX SBOM. Checks the packages listed in it instead of running the licenses script")
+var recursive = flag.Bool("recursive", false, "Check every project found in the current directory and its subdirectories")
+var format = flag.String("format", "text", "Format of the report. One of text, which is logged, json, junit, sarif, markdown or html")
+var reportFile = flag.String("report-file", "", "Path to write the report to. Defaults to stdout")
+var printJSONSchema = flag.Bool("print-json-schema", false, "Print the JSON Schema of the json report and exit")
+var sbomOutput = flag.String("sbom-output", "", "Path to write an SBOM of the checked dependencies to, including the verdict on each of them")
+var sbomOutputFormat = flag.String("sbom-output-format", string(sbom.CycloneDXJSON), "Format of the SBOM written to --sbom-output. Either cyclonedx-json or spdx-json")
+var writePendingDecisionsFlag = flag.Bool("write-pending-decisions", false, "Write the unknown licenses found in non-interactive runs to the pending decisions file for review. The reviewed decisions are applied with the decide command")
 
 func main() {
 	// Parse command line flags
 	flag.Parse()
 
+	if *printJSONSchema {
+		os.Stdout.Write(reporter.JSONSchema)
+		os.Exit(0)
+	}
+
+	// lint reads the config itself, to report the problems that would stop it
+	// from loading
+	if flag.Arg(0) == "lint" {
+		runLint(flag.Args()[1:])
+		return
+	}
+
 	// Set interactive mode if the flag is provided
 	if *interactive {
 		environment.ForceSetIsInteractive(*interactive)
@@ -45,11 +84,34 @@ func main() {
 
 	tui := tui.New()
 
+	// commands that change the licenses file hold its lock from reading it
+	// until they exit, so that concurrent runs don't lose each other's
+	// decisions
+	if modifiesLicensesFile(flag.Arg(0)) {
+		lock := lockFile(config, config.LicensesFile)
+		defer lock.Unlock()
+	}
+
 	licenseChecker, err := setUpLicenseChecker(config)
 	if err != nil {
 		panic(err)
 	}
 
+	// these commands don't check the dependencies in the working tree, so
+	// they don't need it to be set up. The decision commands create the
+	// licenses file if it's missing.
+	switch flag.Arg(0) {
+	case "diff":
+		runDiff(licenseChecker, config, flag.Args()[1:])
+		return
+	case "decide":
+		runDecide(licenseChecker, config)
+		return
+	case "allow", "deny", "forget":
+		runDecisionCommand(licenseChecker, config, flag.Arg(0), flag.Args()[1:])
+		return
+	}
+
 	if environment.IsInteractive() {
 		logFilePath := "license-checker.log"
 		tui.Printf("Logs are written to %s\n", logFilePath)
@@ -77,7 +139,7 @@ func main() {
 				panic(err)
 			}
 		} else if environment.IsInteractive() {
-			askToChooseCuratedList(curatedlistsService, tui)
+			askToChooseCuratedList(curatedlistsService, config, tui)
 			tui.Println()
 		} else {
 			printInteractiveInstructions("No license file found. Please run this tool interactively to set everything up.")
@@ -86,7 +148,7 @@ func main() {
 	}
 
 	// detect if the script for getting current licenses is missing
-	if _, err := os.Stat(config.LicensesScript); os.IsNotExist(err) {
+	if _, err := os.Stat(config.LicensesScript); usesLicensesScript() && os.IsNotExist(err) {
 		if environment.IsInteractive() {
 			wd, err := os.Getwd()
 			if err != nil {
@@ -105,15 +167,40 @@ func main() {
 			os.Exit(1)
 		}
 	}
-	currentLicenses, err := getCurrentLicenses(config.LicensesScript)
-	if err != nil {
-		panic(err)
-	}
 
-	if environment.IsInteractive() {
-		runInteractive(tui, licenseChecker, currentLicenses, config)
+	var dependencies []collector.Dependency
+	var projectLicenses map[string]map[string]string
+	if config.Recursive {
+		projectDependencies, err := getCurrentDependenciesPerProject(config)
+		if err != nil {
+			panic(err)
+		}
+		projectLicenses = lo.MapValues(projectDependencies, func(dependencies []collector.Dependency, _ string) map[string]string {
+			return collector.ToLicenseMap(dependencies)
+		})
+		dependencies = mergeProjectDependencies(projectDependencies)
 	} else {
-		runNonInteractive(licenseChecker, currentLicenses)
+		dependencies, err = getCurrentDependencies(config.LicensesScript, "")
+		if err != nil {
+			panic(err)
+		}
+	}
+	currentLicenses := collector.ToLicenseMap(dependencies)
+
+	switch command := flag.Arg(0); command {
+	case "":
+		if environment.IsInteractive() {
+			runInteractive(tui, licenseChecker, currentLicenses, projectLicenses, dependencies, config)
+		} else {
+			runNonInteractive(licenseChecker, currentLicenses, projectLicenses, dependencies, config)
+		}
+	case "notices":
+		runNotices(licenseChecker, currentLicenses, dependencies, flag.Args()[1:])
+	case "baseline":
+		runBaseline(licenseChecker, currentLicenses, projectLicenses, config, flag.Args()[1:])
+	default:
+		slog.Error("Unknown command", "command", command)
+		os.Exit(2)
 	}
 }
 
@@ -142,77 +229,911 @@ func overwriteConfigWithCmdLineFlags(conf *config.Config) {
 	if conf.LicensesFile == "" {
 		conf.LicensesFile = *licensesFile
 	}
+	if *recursive {
+		conf.Recursive = true
+	}
+	if *writePendingDecisionsFlag {
+		conf.WritePendingDecisions = true
+	}
+}
+
+// modifiesLicensesFile returns true if the command writes decisions to the
+// licenses file
+func modifiesLicensesFile(command string) bool {
+	switch command {
+	case "allow", "deny", "forget", "decide":
+		return true
+	case "":
+		return environment.IsInteractive()
+	default:
+		return false
+	}
+}
+
+// lockFile takes the advisory lock on a file that is read, modified and
+// written, waiting for other runs of the tool holding it. The lock files are
+// kept in the cache dir.
+func lockFile(conf *config.Config, path string) *filelock.Lock {
+	dir := filepath.Join(conf.CacheDir, "locks")
+	lock, err := filelock.TryAcquire(dir, path)
+	if errors.Is(err, filelock.ErrLocked) {
+		slog.Info("Waiting for another run of the tool to finish with the file", "path", path)
+		lock, err = filelock.Acquire(dir, path)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return lock
+}
+
+// withFileLock holds the lock on a file while reading, modifying and writing
+// it in fn
+func withFileLock(conf *config.Config, path string, fn func() error) error {
+	lock := lockFile(conf, path)
+	defer lock.Unlock()
+	return fn()
 }
 
 func setUpLicenseChecker(conf *config.Config) (*checker.LicenseChecker, error) {
+	catalogue, err := obligations.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load obligations catalogue: %w", err)
+	}
+
 	lc, err := checker.NewFromFile(conf.LicensesFile)
 	if os.IsNotExist(err) {
 		// return checker with no decisions made
-		return checker.NewFromMap(make(map[string]bool)), nil
+		lc = checker.NewFromMap(make(map[string]bool))
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to load license checker from file %s: %w", conf.LicensesFile, err)
 	}
+
+	lc.Obligations = catalogue
 	return lc, nil
 }
 
-// TODO: Move and test
-// For JS, look at https://github.com/franciscop/legally
-func getCurrentLicenses(script string) (map[string]string, error) {
-	cmd := exec.Command(script)
+// usesLicensesScript returns false if the dependencies are read from somewhere
+// other than the licenses script
+func usesLicensesScript() bool {
+	return *goBinary == "" && *sbomFile == ""
+}
 
-	stdout, err := cmd.StdoutPipe()
+// newCollector returns the collector matching the flags the tool was started
+// with. Scripts are run in the given directory, or the current directory if
+// dir is empty.
+func newCollector(script string, dir string) collector.Collector {
+	if *sbomFile != "" {
+		return collector.NewSBOMCollector(*sbomFile)
+	}
+	if *goBinary != "" {
+		goBinaryCollector := collector.NewGoBinaryCollector(*goBinary)
+		goBinaryCollector.VendorDir = *vendorDir
+		return goBinaryCollector
+	}
+
+	scriptCollector := collector.NewScriptCollector(script)
+	scriptCollector.Dir = dir
+	return scriptCollector
+}
+
+// getCurrentDependencies gets all dependencies and their licenses, running the
+// licenses script in the given directory, or the current directory if dir is
+// empty
+func getCurrentDependencies(script string, dir string) ([]collector.Dependency, error) {
+	classifier, err := licenseclassifier.New()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stdout for script %s: %w", script, err)
+		return nil, fmt.Errorf("failed to create license classifier: %w", err)
 	}
 
-	err = cmd.Start()
+	c := collector.WithLicenseClassification(newCollector(script, dir), classifier)
+	dependencies, err := c.Collect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start command %s: %w", script, err)
+		return nil, fmt.Errorf("failed to collect current licenses: %w", err)
 	}
 
-	licenses := make(map[string]string)
-	scanner := bufio.NewScanner(stdout)
-	scanner.Split(bufio.ScanLines)
-	for scanner.Scan() {
-		line := scanner.Text()
+	return dependencies, nil
+}
 
-		parts := strings.Split(line, ",")
-		licenses[parts[0]] = parts[1]
+// getCurrentDependenciesPerProject finds all projects in the current directory
+// and its subdirectories, and runs the licenses script in each of them. The
+// returned map is keyed by the path to the project, relative to the current
+// directory.
+func getCurrentDependenciesPerProject(conf *config.Config) (map[string][]collector.Dependency, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
 	}
 
-	err = cmd.Wait()
+	pmd := packagemanagerdetector.New(wd)
+	pmd.IgnoreGlobs = conf.IgnoreGlobs
+	projects, err := pmd.FindProjects()
 	if err != nil {
-		return nil, fmt.Errorf("failed to wait for command %s to finish: %w", script, err)
+		return nil, fmt.Errorf("failed to find projects: %w", err)
 	}
+	if len(projects) == 0 {
+		slog.Warn("No projects found, checking the current directory only", "directory", wd)
+		projects = []packagemanagerdetector.Project{{Root: wd}}
+	}
+
+	projectDependencies := make(map[string][]collector.Dependency)
+	for _, project := range projects {
+		name, err := filepath.Rel(wd, project.Root)
+		if err != nil {
+			name = project.Root
+		}
 
-	return licenses, nil
+		slog.Info("Getting licenses for project", "project", name, "package_managers", project.PackageManagers)
+		dependencies, err := getCurrentDependencies(conf.LicensesScript, project.Root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get licenses for project %s: %w", name, err)
+		}
+		projectDependencies[name] = dependencies
+	}
+
+	return projectDependencies, nil
+}
+
+// mergeProjectDependencies merges the dependencies of all projects into one
+// list, in project order. Dependencies used by several projects are only
+// listed once. If projects disagree on the license or version of a dependency,
+// the first project wins and the conflict is logged, as the verdicts per
+// project still use each project's own license.
+func mergeProjectDependencies(projectDependencies map[string][]collector.Dependency) []collector.Dependency {
+	projects := lo.Keys(projectDependencies)
+	sort.Strings(projects)
+
+	var merged []collector.Dependency
+	// dependency name -> index in merged
+	seen := make(map[string]int)
+	// dependency name -> the project it was first seen in
+	firstProject := make(map[string]string)
+	for _, project := range projects {
+		for _, dependency := range projectDependencies[project] {
+			i, found := seen[dependency.Name]
+			if !found {
+				seen[dependency.Name] = len(merged)
+				firstProject[dependency.Name] = project
+				merged = append(merged, dependency)
+				continue
+			}
+
+			existing := merged[i]
+			if existing.License != dependency.License || existing.Version != dependency.Version {
+				slog.Warn("Dependency differs between projects, using the first project's",
+					"dependency", dependency.Name,
+					"project", firstProject[dependency.Name],
+					"license", existing.License,
+					"version", existing.Version,
+					"other_project", project,
+					"other_license", dependency.License,
+					"other_version", dependency.Version,
+				)
+			}
+		}
+	}
+	return merged
 }
 
-func runNonInteractive(licenseChecker *checker.LicenseChecker, currentLicenses map[string]string) {
+// runNonInteractive checks the current licenses and exits with a non-zero
+// status code if any of them are disallowed or unknown. If projectLicenses is
+// set, the outcome is also logged per project.
+func runNonInteractive(
+	licenseChecker *checker.LicenseChecker,
+	currentLicenses map[string]string,
+	projectLicenses map[string]map[string]string,
+	dependencies []collector.Dependency,
+	conf *config.Config,
+) {
 	slog.Warn(getDisclaimer())
 
-	report, err := licenseChecker.ValidateCurrentLicenses(currentLicenses)
+	var report *checker.Report
+	var err error
+	if projectLicenses != nil {
+		report, err = licenseChecker.ValidateProjects(projectLicenses)
+	} else {
+		report, err = licenseChecker.ValidateCurrentLicenses(currentLicenses)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	report.RecordVersions(collector.ToVersionMap(dependencies))
+	err = withFileLock(conf, conf.LicenseTextsLockfile, func() error {
+		verifier := referencedlicense.NewReferenceValueVerifier(conf.LicenseTextsLockfile)
+		return verifyLicenseTexts(verifier, dependencies, report)
+	})
 	if err != nil {
 		panic(err)
 	}
+	err = withFileLock(conf, conf.LicenseHistoryFile, func() error {
+		return trackLicenseHistory(conf.LicenseHistoryFile, dependencies, report, false)
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err := applyBaseline(conf.BaselineFile, report); err != nil {
+		panic(err)
+	}
+	if conf.WritePendingDecisions {
+		err := withFileLock(conf, conf.PendingDecisionsFile, func() error {
+			return writePendingDecisions(conf.PendingDecisionsFile, report, dependencies)
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if err := writeReports(report, dependencies); err != nil {
+		panic(err)
+	}
+
+	for _, relicensing := range report.SortedRelicensings() {
+		printInteractiveInstructions(
+			"Dependency was relicensed. It is reported until it's acknowledged by running this tool again interactively.",
+			"dependency", relicensing.Dependency,
+			"before", fmt.Sprintf("%s (%s)", relicensing.PreviousLicense, relicensing.PreviousVersion),
+			"after", fmt.Sprintf("%s (%s)", relicensing.License, relicensing.Version),
+		)
+	}
+
+	for _, obligation := range report.SortedObligations() {
+		slog.Info("Obligation", "obligation", obligation.Description, "licenses", obligation.Licenses, "dependencies", obligation.Dependencies)
+	}
 
-	if report.HasDisallowedLicenses() {
-		slog.Error("Disallowed licenses detected", "licenses", report.Disallowed)
+	if report.Baseline != nil {
+		slog.Info("Problems in the baseline are tolerated",
+			"remaining", len(report.Baseline.Debt),
+			"fixed", len(report.Baseline.Fixed),
+			"baseline", conf.BaselineFile,
+		)
+	}
+
+	// without a baseline, these are all problems
+	disallowed := report.WithoutBaselineDebt(report.Disallowed)
+	unknown := report.WithoutBaselineDebt(report.Unknown)
+
+	for _, project := range report.ProjectNames() {
+		projectReport := report.Projects[project]
+		projectDisallowed := report.WithoutBaselineDebt(projectReport.Disallowed)
+		projectUnknown := report.WithoutBaselineDebt(projectReport.Unknown)
+		if len(projectDisallowed) > 0 {
+			slog.Error("Disallowed licenses detected", "project", project, "licenses", projectDisallowed)
+		}
+		if len(projectUnknown) > 0 {
+			slog.Warn("Unknown licenses detected", "project", project, "licenses", projectUnknown)
+		}
+		if len(projectDisallowed) == 0 && len(projectUnknown) == 0 {
+			slog.Info("All licenses are allowed", "project", project)
+		}
+	}
+
+	if len(disallowed) > 0 {
+		slog.Error("Disallowed licenses detected", "licenses", disallowed)
 		os.Exit(1)
 	}
 
-	if report.HasUnknownLicenses() {
+	if report.HasChangedLicenseTexts() {
+		printInteractiveInstructions(
+			"License changed, needs re-review. The license texts of these dependencies changed although their declared licenses didn't. To review them, please run this tool again interactively.",
+			"dependencies", report.ChangedLicenseTexts,
+		)
+		os.Exit(1)
+	}
+
+	if len(unknown) > 0 && conf.WritePendingDecisions {
+		slog.Warn(
+			"Unknown licenses detected. They were written to the pending decisions file. Decide on them there, get the decisions reviewed, and apply them with the decide command.",
+			"licenses", unknown,
+			"pending_decisions", conf.PendingDecisionsFile,
+		)
+		os.Exit(1)
+	}
+	if len(unknown) > 0 {
 		printInteractiveInstructions(
 			"Unknown licenses detected. To decide if they are allowed or not, please run this tool again interactively.",
-			"licenses", report.Unknown,
+			"licenses", unknown,
 		)
 		os.Exit(1)
 	}
 
+	if report.Baseline != nil && len(report.Baseline.Debt) > 0 {
+		slog.Info("No new problems since the baseline was taken")
+		os.Exit(0)
+	}
 	slog.Info("All licenses are allowed")
 	os.Exit(0)
 }
 
+// verifyLicenseTexts compares the license text of every dependency with a
+// license file to the text recorded the last time the tool ran, and records
+// dependencies whose text changed without their declared license changing in
+// the report. The texts of all other dependencies are recorded, and the texts
+// of dependencies that are no longer used are forgotten.
+func verifyLicenseTexts(
+	verifier *referencedlicense.ReferenceValueVerifier,
+	dependencies []collector.Dependency,
+	report *checker.Report,
+) error {
+	var current []referencedlicense.Entry
+	for _, dependency := range dependencies {
+		if dependency.LicenseFile == "" {
+			continue
+		}
+
+		text, err := os.ReadFile(dependency.LicenseFile)
+		if err != nil {
+			slog.Warn("Failed to read license file, skipping license text verification", "dependency", dependency.Name, "error", err)
+			continue
+		}
+
+		key := referencedlicense.Key{Dependency: dependency.Name, Version: dependency.Version}
+		verification, err := verifier.Verify(key, dependency.License, string(text))
+		if err != nil {
+			return fmt.Errorf("failed to verify license text of %s: %w", dependency.Name, err)
+		}
+
+		if verification == referencedlicense.TextChanged {
+			// the recorded text is kept until the new one has been reviewed
+			report.RecordChangedLicenseText(dependency.Name, dependency.License)
+			continue
+		}
+
+		entry := referencedlicense.NewEntry(key, dependency.License, string(text))
+		current = append(current, entry)
+		if err := verifier.Update(entry); err != nil {
+			return fmt.Errorf("failed to record license text of %s: %w", dependency.Name, err)
+		}
+	}
+
+	diff, err := verifier.Diff(current)
+	if err != nil {
+		return fmt.Errorf("failed to compare license texts: %w", err)
+	}
+	for _, key := range diff.Removed {
+		if _, pending := report.ChangedLicenseTexts[key.Dependency]; pending {
+			continue
+		}
+		if err := verifier.Remove(key); err != nil {
+			return fmt.Errorf("failed to forget license text of %s: %w", key.Dependency, err)
+		}
+	}
+
+	return verifier.Save()
+}
+
+// applyBaseline marks the problems in the baseline as baseline debt in the
+// report, if a baseline has been taken
+func applyBaseline(path string, report *checker.Report) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	b, err := baseline.Load(path)
+	if err != nil {
+		return err
+	}
+	report.ApplyBaseline(b.Problems)
+	return nil
+}
+
+// writePendingDecisions writes the unknown licenses in the report to the
+// pending decisions file, keeping the decisions already made there
+func writePendingDecisions(path string, report *checker.Report, dependencies []collector.Dependency) error {
+	f, err := pending.Load(path)
+	if err != nil {
+		return err
+	}
+
+	f.Update(report, dependencies)
+	return f.Write(path)
+}
+
+// runLint reports problems in the config and the licenses file, like
+// misspelled settings and decisions that no longer apply to any dependency.
+// Exits with 1 if any are found.
+func runLint(args []string) {
+	checks := lo.Map(lint.AllChecks, func(check lint.Check, _ int) string { return string(check) })
+
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	disable := flags.String("disable", "", "Comma-separated list of checks to skip. The checks are "+strings.Join(checks, ", "))
+	flags.Parse(args)
+
+	linter, err := lint.New()
+	if err != nil {
+		panic(err)
+	}
+	for _, check := range strings.Split(*disable, ",") {
+		check = strings.TrimSpace(check)
+		if check == "" {
+			continue
+		}
+		if !lo.Contains(checks, check) {
+			slog.Error("Unknown check", "check", check, "checks", checks)
+			os.Exit(2)
+		}
+		linter.Disabled = append(linter.Disabled, lint.Check(check))
+	}
+
+	var findings []lint.Finding
+	if data, err := os.ReadFile(*configFilePath); err == nil {
+		findings = append(findings, linter.Config(*configFilePath, data)...)
+	} else if !os.IsNotExist(err) {
+		panic(fmt.Errorf("failed to read config file %s: %w", *configFilePath, err))
+	}
+
+	conf, err := setUpConfig()
+	if err != nil {
+		if len(findings) == 0 {
+			panic(err)
+		}
+		// the licenses file can't be found without the config
+		printFindings(findings)
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(conf.LicensesFile)
+	if os.IsNotExist(err) {
+		slog.Error("No licenses file found", "path", conf.LicensesFile)
+		os.Exit(1)
+	} else if err != nil {
+		panic(fmt.Errorf("failed to read licenses file %s: %w", conf.LicensesFile, err))
+	}
+
+	if !lo.Contains(linter.Disabled, lint.StaleDecision) {
+		dependencies, err := getLintedDependencies(conf)
+		if err != nil {
+			slog.Warn("Could not get the current dependencies, skipping the stale decision check", "error", err)
+		} else {
+			linter.Dependencies = collector.ToLicenseMap(dependencies)
+		}
+	}
+	findings = append(findings, linter.LicensesFile(conf.LicensesFile, data)...)
+
+	printFindings(findings)
+	if len(findings) > 0 {
+		os.Exit(1)
+	}
+	slog.Info("No problems found", "config_file", *configFilePath, "licenses_file", conf.LicensesFile)
+}
+
+// getLintedDependencies returns the dependencies the stale decisions are
+// looked for in
+func getLintedDependencies(conf *config.Config) ([]collector.Dependency, error) {
+	if _, err := os.Stat(conf.LicensesScript); usesLicensesScript() && err != nil {
+		return nil, fmt.Errorf("failed to find licenses script: %w", err)
+	}
+
+	if !conf.Recursive {
+		return getCurrentDependencies(conf.LicensesScript, "")
+	}
+	projectDependencies, err := getCurrentDependenciesPerProject(conf)
+	if err != nil {
+		return nil, err
+	}
+	return mergeProjectDependencies(projectDependencies), nil
+}
+
+func printFindings(findings []lint.Finding) {
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+}
+
+// runDecisionCommand allows, denies or forgets licenses, for all dependencies
+// or for a single dependency, and writes the licenses file
+func runDecisionCommand(licenseChecker *checker.LicenseChecker, conf *config.Config, command string, args []string) {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	dependency := flags.String("dependency", "", "Only "+command+" the licenses for this dependency")
+	var reason *string
+	if command != "forget" {
+		reason = flags.String("reason", "", "Why the licenses are "+map[string]string{"allow": "allowed", "deny": "denied"}[command]+". Written as a comment in the licenses file, replacing any previous reason")
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: license-checker %s [flags] <license>...\n", command)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	for _, license := range flags.Args() {
+		if !checker.IsSingleLicense(license) {
+			slog.Error("Decisions are made on single licenses, not license expressions", "license", license)
+			os.Exit(2)
+		}
+	}
+
+	for _, license := range flags.Args() {
+		switch {
+		case command == "forget" && *dependency == "":
+			if !licenseChecker.Remove(license) {
+				slog.Warn("No decision has been made for the license", "license", license)
+			}
+		case command == "forget":
+			if !licenseChecker.RemoveForDependency(*dependency, license) {
+				slog.Warn("No decision has been made for the license for this dependency", "license", license, "dependency", *dependency)
+			}
+		case *dependency == "":
+			licenseChecker.Update(license, command == "allow")
+			if *reason != "" {
+				licenseChecker.SetReason(license, *reason)
+			}
+		default:
+			licenseChecker.UpdateForDependency(*dependency, license, command == "allow")
+			if *reason != "" {
+				licenseChecker.SetReasonForDependency(*dependency, license, *reason)
+			}
+		}
+	}
+
+	if err := licenseChecker.Write(conf.LicensesFile); err != nil {
+		panic(err)
+	}
+
+	logArgs := []any{"licenses", flags.Args(), "licenses_file", conf.LicensesFile}
+	if *dependency != "" {
+		logArgs = append(logArgs, "dependency", *dependency)
+	}
+	slog.Info(map[string]string{"allow": "Allowed", "deny": "Denied", "forget": "Forgot"}[command], logArgs...)
+}
+
+// runDecide moves the reviewed decisions in the pending decisions file into
+// the licenses file. Licenses without a decision stay pending.
+func runDecide(licenseChecker *checker.LicenseChecker, conf *config.Config) {
+	defer lockFile(conf, conf.PendingDecisionsFile).Unlock()
+
+	f, err := pending.Load(conf.PendingDecisionsFile)
+	if err != nil {
+		panic(err)
+	}
+
+	decided, err := f.Decided()
+	if err != nil {
+		slog.Error("Invalid pending decisions", "path", conf.PendingDecisionsFile, "error", err)
+		os.Exit(1)
+	}
+	if len(decided) == 0 {
+		slog.Info("No pending decisions to apply", "path", conf.PendingDecisionsFile, "pending", len(f.Licenses))
+		return
+	}
+
+	for _, license := range decided {
+		licenseChecker.Update(license.License, license.Decision == pending.Allow)
+		f.Remove(license.License)
+		slog.Info("Applying decision", "license", license.License, "decision", license.Decision, "dependencies", len(license.Dependencies))
+	}
+
+	if err := licenseChecker.Write(conf.LicensesFile); err != nil {
+		panic(err)
+	}
+	if err := f.Write(conf.PendingDecisionsFile); err != nil {
+		panic(err)
+	}
+
+	slog.Info("Applied pending decisions", "licenses_file", conf.LicensesFile, "applied", len(decided), "still_pending", len(f.Licenses))
+}
+
+// trackLicenseHistory records the dependencies whose license changed since
+// the last time the tool ran in the report, and remembers the current version
+// and license of every dependency. Unless the relicensings are acknowledged,
+// the previous licenses of relicensed dependencies are kept so that they are
+// reported again next time.
+func trackLicenseHistory(
+	path string,
+	dependencies []collector.Dependency,
+	report *checker.Report,
+	acknowledgeRelicensings bool,
+) error {
+	history, err := licensehistory.Load(path)
+	if err != nil {
+		return err
+	}
+
+	for _, relicensing := range history.Compare(dependencies) {
+		report.RecordRelicensing(relicensing)
+	}
+
+	if !acknowledgeRelicensings {
+		dependencies = lo.Filter(dependencies, func(dependency collector.Dependency, _ int) bool {
+			_, relicensed := report.Relicensed[dependency.Name]
+			return !relicensed
+		})
+	}
+	history.Update(dependencies)
+	return history.Save()
+}
+
+// writeReports writes the report to the outputs requested on the command line
+func writeReports(report *checker.Report, dependencies []collector.Dependency) error {
+	switch *format {
+	case "text":
+		// the report is logged as the licenses are checked
+	case "json":
+		err := writeReportFile(func(w io.Writer) error {
+			return reporter.WriteJSON(w, report)
+		})
+		if err != nil {
+			return err
+		}
+	case "junit":
+		err := writeReportFile(func(w io.Writer) error {
+			return reporter.WriteJUnit(w, report)
+		})
+		if err != nil {
+			return err
+		}
+	case "markdown":
+		err := writeReportFile(func(w io.Writer) error {
+			return reporter.WriteMarkdown(w, report, getDisclaimer())
+		})
+		if err != nil {
+			return err
+		}
+	case "html":
+		err := writeReportFile(func(w io.Writer) error {
+			return reporter.WriteHTML(w, report, getDisclaimer())
+		})
+		if err != nil {
+			return err
+		}
+	case "sarif":
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		err = writeReportFile(func(w io.Writer) error {
+			return reporter.WriteSARIF(w, report, collector.ToLocationMap(dependencies), wd)
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown report format '%s'", *format)
+	}
+
+	if *sbomOutput != "" {
+		return writeSBOM(report)
+	}
+	return nil
+}
+
+// writeReportFile calls write with the file given by --report-file, or stdout
+// if no file is given
+func writeReportFile(write func(w io.Writer) error) error {
+	if *reportFile == "" {
+		return write(os.Stdout)
+	}
+
+	if err := atomicfile.WriteFile(*reportFile, 0644, write); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+
+	slog.Info("Wrote report", "path", *reportFile, "format", *format)
+	return nil
+}
+
+func writeSBOM(report *checker.Report) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	err = atomicfile.WriteFile(*sbomOutput, 0644, func(w io.Writer) error {
+		return sbom.Write(w, sbom.Format(*sbomOutputFormat), filepath.Base(wd), report)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write SBOM: %w", err)
+	}
+
+	slog.Info("Wrote SBOM", "path", *sbomOutput, "format", *sbomOutputFormat)
+	return nil
+}
+
+// runNotices writes a THIRD_PARTY_NOTICES file with the attribution of every
+// allowed dependency
+func runNotices(
+	licenseChecker *checker.LicenseChecker,
+	currentLicenses map[string]string,
+	dependencies []collector.Dependency,
+	args []string,
+) {
+	flags := flag.NewFlagSet("notices", flag.ExitOnError)
+	format := flags.String("format", string(notices.FormatText), "Format of the notices file. One of text, markdown or html")
+	template := flags.String("template", "", "Path to a Go template to render the notices with instead of the built-in one for the format")
+	output := flags.String("output", "", "Path to write the notices to. Defaults to THIRD_PARTY_NOTICES, with a file extension matching the format")
+	flags.Parse(args)
+
+	if *output == "" {
+		*output = "THIRD_PARTY_NOTICES"
+		switch notices.Format(*format) {
+		case notices.FormatMarkdown:
+			*output += ".md"
+		case notices.FormatHTML:
+			*output += ".html"
+		}
+	}
+
+	report, err := licenseChecker.ValidateCurrentLicenses(currentLicenses)
+	if err != nil {
+		panic(err)
+	}
+	if report.HasDisallowedLicenses() || report.HasUnknownLicenses() {
+		slog.Warn(
+			"Only allowed dependencies are included in the notices",
+			"disallowed", report.Disallowed,
+			"unknown", report.Unknown,
+		)
+	}
+
+	n, err := notices.Build(report, dependencies, licenseChecker)
+	if err != nil {
+		panic(err)
+	}
+
+	err = atomicfile.WriteFile(*output, 0644, func(w io.Writer) error {
+		return notices.Write(w, n, notices.Format(*format), *template)
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to write notices file: %w", err))
+	}
+
+	slog.Info("Wrote notices", "path", *output, "dependencies", len(n.Dependencies), "license_texts", len(n.Texts))
+}
+
+// runDiff reports the dependencies that were added, removed or changed license
+// between two git revisions, and exits with status code 1 if any of the added
+// or changed licenses are disallowed or unknown. Revisions that can't be
+// checked out and scripts that fail exit with status code 2.
+func runDiff(licenseChecker *checker.LicenseChecker, conf *config.Config, args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", "text", "Format of the diff. One of text, json or markdown")
+	output := flags.String("output", "", "Path to write the diff to. Defaults to stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: license-checker diff [flags] <base revision> [<head revision>]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if !lo.Contains([]string{"text", "json", "markdown"}, *format) {
+		slog.Error("Unknown diff format", "format", *format)
+		os.Exit(2)
+	}
+	base := flags.Arg(0)
+	head := "HEAD"
+	if flags.NArg() == 2 {
+		head = flags.Arg(1)
+	}
+
+	if !usesLicensesScript() {
+		slog.Error("The diff command only works with the licenses script, not with --sbom or --go-binary")
+		os.Exit(2)
+	}
+	if conf.Recursive {
+		slog.Warn("The diff command only checks the current directory, not every project below it")
+	}
+
+	baseDependencies, err := getDependenciesAtRevision(conf.LicensesScript, base)
+	if err != nil {
+		slog.Error("Failed to get the dependencies of the base revision", "revision", base, "error", err)
+		os.Exit(2)
+	}
+	headDependencies, err := getDependenciesAtRevision(conf.LicensesScript, head)
+	if err != nil {
+		slog.Error("Failed to get the dependencies of the head revision", "revision", head, "error", err)
+		os.Exit(2)
+	}
+
+	diff := depdiff.Compare(baseDependencies, headDependencies)
+	if err := diff.Check(licenseChecker); err != nil {
+		slog.Error("Failed to check the changed licenses", "error", err)
+		os.Exit(2)
+	}
+
+	write := func(w io.Writer) error {
+		switch *format {
+		case "text":
+			return reporter.WriteDiffText(w, diff, base, head)
+		case "json":
+			return reporter.WriteDiffJSON(w, diff, base, head)
+		case "markdown":
+			return reporter.WriteDiffMarkdown(w, diff, base, head, getDisclaimer())
+		default:
+			return fmt.Errorf("unknown diff format '%s'", *format)
+		}
+	}
+	if *output == "" {
+		err = write(os.Stdout)
+	} else {
+		err = atomicfile.WriteFile(*output, 0644, write)
+	}
+	if err != nil {
+		slog.Error("Failed to write the diff", "error", err)
+		os.Exit(2)
+	}
+
+	if problems := diff.Problems(); len(problems) > 0 {
+		slog.Error("Disallowed or unknown licenses introduced",
+			"dependencies", lo.Map(problems, func(change depdiff.Change, _ int) string {
+				return fmt.Sprintf("%s (%s): %s", change.Dependency, change.HeadLicense, change.Decision.Verdict)
+			}),
+		)
+		os.Exit(1)
+	}
+}
+
+// getDependenciesAtRevision runs the licenses script in a temporary checkout
+// of a git revision. The script is run in the directory matching the current
+// directory, and the script from the working tree is used for all revisions.
+func getDependenciesAtRevision(script string, revision string) ([]collector.Dependency, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	worktree, err := git.AddWorktree(wd, revision)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := worktree.Remove(); err != nil {
+			slog.Warn("Failed to remove temporary worktree", "dir", worktree.Dir, "error", err)
+		}
+	}()
+
+	dir, err := worktree.Path(wd)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Getting licenses at revision", "revision", revision, "dir", dir)
+	dependencies, err := getCurrentDependencies(script, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get licenses at revision %s: %w", revision, err)
+	}
+	return dependencies, nil
+}
+
+// runBaseline takes a baseline of the current problems. From then on, only
+// problems that aren't in the baseline fail the check.
+func runBaseline(
+	licenseChecker *checker.LicenseChecker,
+	currentLicenses map[string]string,
+	projectLicenses map[string]map[string]string,
+	conf *config.Config,
+	args []string,
+) {
+	flags := flag.NewFlagSet("baseline", flag.ExitOnError)
+	output := flags.String("output", conf.BaselineFile, "Path to write the baseline to")
+	flags.Parse(args)
+
+	var report *checker.Report
+	var err error
+	if projectLicenses != nil {
+		report, err = licenseChecker.ValidateProjects(projectLicenses)
+	} else {
+		report, err = licenseChecker.ValidateCurrentLicenses(currentLicenses)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	b := baseline.New(report)
+	if err := b.Write(*output); err != nil {
+		panic(err)
+	}
+
+	slog.Info("Wrote baseline", "path", *output, "problems", len(b.Problems))
+	if *output != conf.BaselineFile {
+		slog.Warn("The baseline is only used if it's written to the baseline-file in the config", "baseline-file", conf.BaselineFile)
+	}
+}
+
 func printInteractiveInstructions(message string, args ...any) {
 	// TODO: verify hint
 	args = append(args, "hint", "For example, run `./license-checker .` from the project root.")
@@ -227,10 +1148,33 @@ func getDisclaimer() string {
 	return "DISCLAIMER: THIS IS NOT LEGAL ADVICE. YOU ARE RESPONSIBLE FOR ENSURING THAT YOUR PROJECT COMPLIES WITH ALL APPLICABLE LAWS AND LICENSES."
 }
 
+// withProjects adds the projects using each dependency to its name, like
+// `react (in frontend, docs)`, when several projects are checked
+func withProjects(dependencies []string, projectLicenses map[string]map[string]string) []string {
+	if projectLicenses == nil {
+		return dependencies
+	}
+
+	projects := lo.Keys(projectLicenses)
+	sort.Strings(projects)
+
+	described := make([]string, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		usedBy := lo.Filter(projects, func(project string, _ int) bool {
+			_, found := projectLicenses[project][dependency]
+			return found
+		})
+		described = append(described, fmt.Sprintf("%s (in %s)", dependency, strings.Join(usedBy, ", ")))
+	}
+	return described
+}
+
 func runInteractive(
 	tui *tui.TUI,
 	licenseChecker *checker.LicenseChecker,
 	currentLicenses map[string]string,
+	projectLicenses map[string]map[string]string,
+	dependencies []collector.Dependency,
 	conf *config.Config,
 ) {
 	phraser := phraser.New([]string{
@@ -242,7 +1186,10 @@ func runInteractive(
 		"Let's consider %s",
 	})
 
-	licenseDescriber := licensedescriber.NewTLDRDescriber()
+	licenseDescriber, err := newLicenseDescriber(conf)
+	if err != nil {
+		panic(err)
+	}
 
 	// validate licenses until there are no unknown licenses
 	for {
@@ -253,6 +1200,7 @@ func runInteractive(
 
 		if report.HasDisallowedLicenses() {
 			for license, dependencies := range report.Disallowed {
+				dependencies = withProjects(dependencies, projectLicenses)
 				tui.Printf("Disallowed license %s detected\n", license)
 				if len(dependencies) == 1 {
 					tui.Printf("It's currently only used by dependency %s\n", dependencies[0])
@@ -281,6 +1229,7 @@ func runInteractive(
 			tui.Println()
 
 			for license, dependencies := range report.Unknown {
+				dependencies = withProjects(dependencies, projectLicenses)
 				tui.Println(phraser.Get(license))
 
 				description, err := licenseDescriber.Describe(license)
@@ -314,9 +1263,111 @@ func runInteractive(
 			break
 		}
 	}
+
+	// the decisions made above are part of the reports
+	report, err := licenseChecker.ValidateCurrentLicenses(currentLicenses)
+	if err != nil {
+		panic(err)
+	}
+
+	report.RecordVersions(collector.ToVersionMap(dependencies))
+	// held while the changed license texts are reviewed below
+	defer lockFile(conf, conf.LicenseTextsLockfile).Unlock()
+	verifier := referencedlicense.NewReferenceValueVerifier(conf.LicenseTextsLockfile)
+	if err := verifyLicenseTexts(verifier, dependencies, report); err != nil {
+		panic(err)
+	}
+	// the relicensings are shown to the user below
+	err = withFileLock(conf, conf.LicenseHistoryFile, func() error {
+		return trackLicenseHistory(conf.LicenseHistoryFile, dependencies, report, true)
+	})
+	if err != nil {
+		panic(err)
+	}
+	for _, relicensing := range report.SortedRelicensings() {
+		tui.Printf("%s was relicensed from %s (%s) to %s (%s)\n",
+			relicensing.Dependency,
+			relicensing.PreviousLicense, relicensing.PreviousVersion,
+			relicensing.License, relicensing.Version,
+		)
+	}
+	if report.HasChangedLicenseTexts() {
+		askToReviewChangedLicenseTexts(tui, verifier, dependencies, report)
+	}
+
+	if err := writeReports(report, dependencies); err != nil {
+		panic(err)
+	}
 }
 
-func askToChooseCuratedList(s *curatedlists.Service, tui *tui.TUI) {
+// askToReviewChangedLicenseTexts asks the user to review the license texts
+// that changed without the declared license changing. Texts the user accepts
+// are recorded, so they aren't reported again.
+func askToReviewChangedLicenseTexts(
+	tui *tui.TUI,
+	verifier *referencedlicense.ReferenceValueVerifier,
+	dependencies []collector.Dependency,
+	report *checker.Report,
+) {
+	tui.Printf("The license texts of %d dependencies changed although their declared licenses didn't\n", len(report.ChangedLicenseTexts))
+	tui.Println("This can mean that they were relicensed, so please review the new texts")
+	tui.Println()
+
+	for _, dependency := range dependencies {
+		license, changed := report.ChangedLicenseTexts[dependency.Name]
+		if !changed {
+			continue
+		}
+
+		tui.Printf("License changed, needs re-review: %s (%s)\n", dependency.Name, license)
+		tui.Printf("The new license text is in %s\n", dependency.LicenseFile)
+		if !tui.AskYesNo("Is the new license text still acceptable?") {
+			tui.Println("Okay, please remove the dependency or pin it to a version with the old license")
+			tui.Println()
+			continue
+		}
+
+		text, err := os.ReadFile(dependency.LicenseFile)
+		if err != nil {
+			panic(fmt.Errorf("failed to read license file of %s: %w", dependency.Name, err))
+		}
+		key := referencedlicense.Key{Dependency: dependency.Name, Version: dependency.Version}
+		if err := verifier.Update(referencedlicense.NewEntry(key, dependency.License, string(text))); err != nil {
+			panic(err)
+		}
+		if err := verifier.Save(); err != nil {
+			panic(err)
+		}
+		delete(report.ChangedLicenseTexts, dependency.Name)
+		tui.Println()
+	}
+}
+
+// newLicenseDescriber returns a describer that shows the company's guidance on
+// a license if there is any, and a generic summary otherwise
+func newLicenseDescriber(conf *config.Config) (licensedescriber.Describer, error) {
+	var describers []licensedescriber.Describer
+	if conf.DescriptionsDir != "" {
+		describers = append(describers, licensedescriber.NewDirectoryDescriber(conf.DescriptionsDir))
+	}
+	if conf.DescriptionsURL != "" {
+		describers = append(describers, licensedescriber.NewCachedDescriber(
+			licensedescriber.NewHTTPDescriber(conf.DescriptionsURL),
+			filepath.Join(conf.CacheDir, "descriptions"),
+			conf.DescriptionsCacheTTL,
+		))
+	}
+
+	embedded, err := licensedescriber.NewEmbeddedDescriber()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create license describer: %w", err)
+	}
+	describers = append(describers, embedded)
+
+	return licensedescriber.NewChain(describers...), nil
+}
+
+func askToChooseCuratedList(s *curatedlists.Service, conf *config.Config, tui *tui.TUI) {
 	tui.Println("It seems no choices around which licenses are allowed or not have been made yet.")
 	tui.Println("We can download some predefined lists of licenses to get you started.")
 	tui.Println("They aren't perfect and you're likely to have to make some adjustments, but we'll go through all that together")
@@ -357,7 +1408,9 @@ func askToChooseCuratedList(s *curatedlists.Service, tui *tui.TUI) {
 
 		if tui.AskYesNo("Do you want to use this list?") {
 			tui.Println("Great! It's all been set up for you.")
-			err = s.SelectList(suggestedList)
+			err = withFileLock(conf, conf.Path, func() error {
+				return s.SelectList(suggestedList)
+			})
 			if err != nil {
 				panic(err)
 			}
@@ -395,7 +1448,9 @@ func askToChooseCuratedList(s *curatedlists.Service, tui *tui.TUI) {
 	}
 
 	tui.Println("Great! It's all been set up for you.")
-	err = s.SelectList(answers[answer])
+	err = withFileLock(conf, conf.Path, func() error {
+		return s.SelectList(answers[answer])
+	})
 	if err != nil {
 		panic(err)
 	}
@@ -412,20 +1467,26 @@ func askToChooseLicensesScript(
 	tui.Println()
 	//tui.Println("The script should output a list of projects and their licenses, separated by commas.")
 
-	detectedPackageManagers, err := pmd.FindLikelyPackageManagers()
+	detections, err := pmd.FindLikelyPackageManagers()
 	if err != nil {
 		panic(err)
 	}
 
-	if len(detectedPackageManagers) == 1 {
-		scriptExists, err := cls.HasScriptForPackageManager(detectedPackageManagers[0])
+	if len(detections) == 1 {
+		packageManager := detections[0].PackageManager
+		scriptExists, err := cls.HasScriptForPackageManager(packageManager)
 		if err != nil {
-			slog.Warn("Failed to check if script exists for package manager", "package_manager", detectedPackageManagers[0], "error", err)
+			slog.Warn("Failed to check if script exists for package manager", "package_manager", packageManager, "error", err)
 		} else if scriptExists {
-			tui.Printf("It seems your project uses %s\n", detectedPackageManagers[0])
-			if tui.AskYesNo("Do you want to use a preset script reading licenses for %s?", detectedPackageManagers[0]) {
+			tui.Printf("It seems your project uses %s\n", packageManager)
+			if tui.AskYesNo("Do you want to use a preset script reading licenses for %s?", packageManager) {
 				tui.Println("Great! Setting that up for you...")
-				cls.SelectScript(detectedPackageManagers[0])
+				err := withFileLock(conf, conf.Path, func() error {
+					return cls.SelectScript(packageManager)
+				})
+				if err != nil {
+					panic(err)
+				}
 				return
 			} else {
 				tui.Println("Fair enough, let's set up a script for you to use")
@@ -433,24 +1494,43 @@ func askToChooseLicensesScript(
 			}
 		}
 
-	} else if len(detectedPackageManagers) > 1 {
+	} else if len(detections) > 1 {
 		tui.Println("More than one package manager was detected in your project.")
 		tui.Println("You will likely want to set up your own script reading dependencies from all of them.")
 		tui.Println("However, a preset script for one of them can be provided to get you started.")
 		tui.Println()
+		tui.Printf(
+			"The most likely one is %s, based on %s\n",
+			detections[0].PackageManager,
+			strings.Join(detections[0].Evidence, ", "),
+		)
+		tui.Println()
 
-		choices := append(detectedPackageManagers, "No - I'll provide my own script")
+		// detections are sorted with the most likely package manager first
+		choices := lo.Map(detections, func(detection packagemanagerdetector.Detection, i int) string {
+			choice := fmt.Sprintf("%s (found %s)", detection.PackageManager, strings.Join(detection.Evidence, ", "))
+			if i == 0 {
+				choice += " - recommended"
+			}
+			return choice
+		})
+		choices = append(choices, "No - I'll provide my own script")
 		choice := tui.AskMultipleChoice("Do you want to use a preset script for any of these?", choices...)
 		if choice == len(choices)-1 {
 			tui.Println("Good call")
 			tui.Println()
 		} else {
 			tui.Printf("Great! Setting that up for you...")
-			cls.SelectScript(detectedPackageManagers[choice])
+			err := withFileLock(conf, conf.Path, func() error {
+				return cls.SelectScript(detections[choice].PackageManager)
+			})
+			if err != nil {
+				panic(err)
+			}
 			return
 		}
 
-	} else if len(detectedPackageManagers) == 0 {
+	} else if len(detections) == 0 {
 		tui.Println("I couldn't detect any package managers in your project and can't help you with a reasonable default script :(")
 	}
 
@@ -458,7 +1538,7 @@ func askToChooseLicensesScript(
 	tui.Println("Great! Setting that up for you...")
 
 	conf.LicensesScript = path
-	if err = conf.Write(); err != nil {
+	if err = withFileLock(conf, conf.Path, conf.Write); err != nil {
 		panic(err)
 	}
 }
@@ -0,0 +1,117 @@
+package notices
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+	"github.com/eriklarko/license-checker/src/licenseclassifier"
+)
+
+// Notices is the content of a THIRD_PARTY_NOTICES file; the attribution of
+// every allowed dependency
+type Notices struct {
+	// Sorted by name
+	Dependencies []Dependency
+	// Every distinct license text, in the order they're first referenced by
+	// Dependencies
+	Texts []LicenseText
+}
+
+// Dependency is the attribution of a single dependency
+type Dependency struct {
+	Name    string
+	Version string
+	License string
+	// The copyright lines found in the dependency's license file
+	Copyrights []string
+	// The IDs of the dependency's license texts in Notices.Texts. Empty if no
+	// license text was found.
+	Texts []int
+}
+
+// LicenseText is a license text shared by one or more dependencies
+type LicenseText struct {
+	// The text's 1-based position in Notices.Texts, for anchors and
+	// references in the rendered file
+	ID int
+	// The license the text is for, or the license expression of the
+	// dependency that shipped it
+	License string
+	// The license text, without copyright lines
+	Text string
+	// The names of the dependencies using this text
+	Dependencies []string
+}
+
+// Build collects the attribution of every allowed dependency in the report.
+//
+// The license text of a dependency is read from its license file if the
+// collector found one. Otherwise the texts of the licenses the dependency is
+// allowed under are taken from the license classifier's corpus, leaving out
+// the alternatives the policy denies. Texts that are identical once copyright
+// lines are removed are only included once.
+func Build(report *checker.Report, dependencies []collector.Dependency, licenseChecker *checker.LicenseChecker) (*Notices, error) {
+	byName := make(map[string]collector.Dependency, len(dependencies))
+	for _, dependency := range dependencies {
+		byName[dependency.Name] = dependency
+	}
+
+	n := &Notices{}
+	textIndices := make(map[string]int)
+	addText := func(license string, text string, dependency string) int {
+		i, found := textIndices[text]
+		if !found {
+			i = len(n.Texts)
+			textIndices[text] = i
+			n.Texts = append(n.Texts, LicenseText{ID: i + 1, License: license, Text: text})
+		}
+		n.Texts[i].Dependencies = append(n.Texts[i].Dependencies, dependency)
+		return n.Texts[i].ID
+	}
+
+	for _, decision := range report.SortedDecisions() {
+		if decision.Verdict != checker.VerdictAllowed {
+			continue
+		}
+
+		dependency := byName[decision.Dependency]
+		notice := Dependency{
+			Name:    decision.Dependency,
+			Version: dependency.Version,
+			License: decision.License,
+		}
+
+		if dependency.LicenseFile != "" {
+			content, err := os.ReadFile(dependency.LicenseFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read license file of %s: %w", decision.Dependency, err)
+			}
+
+			text, copyrights := licenseclassifier.SplitCopyright(string(content))
+			notice.Copyrights = copyrights
+			notice.Texts = append(notice.Texts, addText(decision.License, text, decision.Dependency))
+		} else {
+			licenses := licenseChecker.AllowedLicenses(decision)
+			sort.Strings(licenses)
+			for _, license := range licenses {
+				text, found := licenseclassifier.LicenseText(license)
+				if !found {
+					continue
+				}
+				notice.Texts = append(notice.Texts, addText(license, text, decision.Dependency))
+			}
+		}
+
+		if len(notice.Texts) == 0 {
+			slog.Warn("No license text found", "dependency", decision.Dependency, "license", decision.License)
+		}
+
+		n.Dependencies = append(n.Dependencies, notice)
+	}
+
+	return n, nil
+}
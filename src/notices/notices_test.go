@@ -0,0 +1,119 @@
+package notices_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+	helpers_test "github.com/eriklarko/license-checker/src/helpers"
+	"github.com/eriklarko/license-checker/src/notices"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bespokeLicense = `Copyright (c) 2024 Some Person
+
+Do whatever you want, just don't blame me.
+`
+
+const sameBespokeLicense = `Copyright 2023 Another Person
+
+Do whatever you want, just don't blame me.
+`
+
+func TestBuildAndWrite(t *testing.T) {
+	dependencies := []collector.Dependency{
+		{Name: "bespoke-1", Version: "1.0.0", License: "LicenseRef-Bespoke", LicenseFile: helpers_test.CreateTempFileWithContents(t, bespokeLicense)},
+		{Name: "bespoke-2", Version: "2.0.0", License: "LicenseRef-Bespoke", LicenseFile: helpers_test.CreateTempFileWithContents(t, sameBespokeLicense)},
+		{Name: "dual", License: "MIT || Apache-2.0"},
+		{Name: "mit", License: "MIT"},
+		{Name: "disallowed", License: "GPL-3.0-only"},
+		{Name: "unknown", License: "ISC"},
+	}
+
+	lc := checker.NewFromLists([]string{"LicenseRef-Bespoke", "MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(collector.ToLicenseMap(dependencies))
+	require.NoError(t, err)
+
+	n, err := notices.Build(report, dependencies, lc)
+	require.NoError(t, err)
+
+	// only allowed dependencies are included
+	require.Len(t, n.Dependencies, 4)
+	assert.Equal(t, notices.Dependency{
+		Name:       "bespoke-1",
+		Version:    "1.0.0",
+		License:    "LicenseRef-Bespoke",
+		Copyrights: []string{"Copyright (c) 2024 Some Person"},
+		Texts:      []int{1},
+	}, n.Dependencies[0])
+	assert.Equal(t, []string{"Copyright 2023 Another Person"}, n.Dependencies[1].Copyrights)
+	// the identical texts are only included once
+	assert.Equal(t, []int{1}, n.Dependencies[1].Texts)
+	// dependencies without license files get the texts from the corpus
+	assert.Equal(t, []int{2, 3}, n.Dependencies[2].Texts)
+	assert.Equal(t, []int{3}, n.Dependencies[3].Texts)
+
+	require.Len(t, n.Texts, 3)
+	assert.Equal(t, "Do whatever you want, just don't blame me.", n.Texts[0].Text)
+	assert.Equal(t, []string{"bespoke-1", "bespoke-2"}, n.Texts[0].Dependencies)
+	assert.Equal(t, "Apache-2.0", n.Texts[1].License)
+	assert.Equal(t, "MIT", n.Texts[2].License)
+	assert.Equal(t, []string{"dual", "mit"}, n.Texts[2].Dependencies)
+
+	for _, format := range []notices.Format{notices.FormatText, notices.FormatMarkdown, notices.FormatHTML} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, notices.Write(&buf, n, format, ""))
+
+			assert.Contains(t, buf.String(), "Copyright (c) 2024 Some Person")
+			assert.Contains(t, buf.String(), "Permission is hereby granted")
+			assert.NotContains(t, buf.String(), "disallowed")
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		err := notices.Write(&bytes.Buffer{}, n, "pdf", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("custom template", func(t *testing.T) {
+		template := helpers_test.CreateTempFileWithContents(t, `{{ range .Dependencies }}{{ .Name }}: {{ .License }}
+{{ end }}`)
+
+		var buf bytes.Buffer
+		require.NoError(t, notices.Write(&buf, n, notices.FormatText, template))
+
+		assert.Equal(t, `bespoke-1: LicenseRef-Bespoke
+bespoke-2: LicenseRef-Bespoke
+dual: MIT || Apache-2.0
+mit: MIT
+`, buf.String())
+	})
+}
+
+func TestBuild_DeniedAlternative(t *testing.T) {
+	dependencies := []collector.Dependency{{Name: "dual", License: "MIT || GPL-3.0-only"}}
+	lc := checker.NewFromLists([]string{"MIT"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(collector.ToLicenseMap(dependencies))
+	require.NoError(t, err)
+
+	n, err := notices.Build(report, dependencies, lc)
+	require.NoError(t, err)
+
+	// the dependency is used under MIT, so the GPL text isn't included
+	require.Len(t, n.Texts, 1)
+	assert.Equal(t, "MIT", n.Texts[0].License)
+	assert.Equal(t, []int{1}, n.Dependencies[0].Texts)
+}
+
+func TestWrite_HTMLEscapes(t *testing.T) {
+	template := helpers_test.CreateTempFileWithContents(t, `{{ range .Dependencies }}{{ .Name }}{{ end }}`)
+	n := &notices.Notices{Dependencies: []notices.Dependency{{Name: "<script>"}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, notices.Write(&buf, n, notices.FormatHTML, template))
+
+	assert.Equal(t, "&lt;script&gt;", buf.String())
+}
@@ -0,0 +1,86 @@
+package notices
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Format is the file format of a rendered notices file
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// functions available in text and Markdown templates
+var templateFuncs = texttemplate.FuncMap{
+	// escapes the pipes in license expressions, which would otherwise end
+	// Markdown table cells
+	"cell": func(s string) string { return strings.ReplaceAll(s, "|", `\|`) },
+}
+
+// executor is implemented by both text/template and html/template templates
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// Write renders the notices in the given format. If templatePath is set, that
+// template is used instead of the built-in one for the format. Templates are
+// Go templates executed with a *Notices; HTML templates are parsed with
+// html/template so that everything inserted into them is escaped.
+func Write(w io.Writer, n *Notices, format Format, templatePath string) error {
+	tmpl, err := loadTemplate(format, templatePath)
+	if err != nil {
+		return err
+	}
+
+	if err := tmpl.Execute(w, n); err != nil {
+		return fmt.Errorf("failed to render notices: %w", err)
+	}
+	return nil
+}
+
+func loadTemplate(format Format, templatePath string) (executor, error) {
+	var name string
+	switch format {
+	case FormatText:
+		name = "notices.txt.tmpl"
+	case FormatMarkdown:
+		name = "notices.md.tmpl"
+	case FormatHTML:
+		name = "notices.html.tmpl"
+	default:
+		return nil, fmt.Errorf("unknown notices format '%s'", format)
+	}
+
+	content, err := templates.ReadFile("templates/" + name)
+	if templatePath != "" {
+		name = templatePath
+		content, err = os.ReadFile(templatePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	var tmpl executor
+	if format == FormatHTML {
+		tmpl, err = htmltemplate.New(name).Parse(string(content))
+	} else {
+		tmpl, err = texttemplate.New(name).Funcs(templateFuncs).Parse(string(content))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	return tmpl, nil
+}
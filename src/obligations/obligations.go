@@ -0,0 +1,99 @@
+package obligations
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed catalogue.yaml
+var embeddedCatalogue []byte
+
+// Obligation is something that has to be done when using software under a
+// license, e.g. including the license text when distributing it
+type Obligation struct {
+	ID          string
+	Description string
+}
+
+// Catalogue lists the obligations (conditions) and limitations of licenses,
+// keyed by SPDX ID
+type Catalogue struct {
+	Conditions  map[string]string       `yaml:"conditions"`
+	Limitations map[string]string       `yaml:"limitations"`
+	Licenses    map[string]LicenseTerms `yaml:"licenses"`
+}
+
+// LicenseTerms are the IDs of the conditions and limitations of a license
+type LicenseTerms struct {
+	Conditions  []string `yaml:"conditions"`
+	Limitations []string `yaml:"limitations"`
+}
+
+// Load returns the catalogue embedded in the tool
+func Load() (*Catalogue, error) {
+	return Parse(embeddedCatalogue)
+}
+
+// Parse reads a catalogue in the format of the embedded one, and verifies
+// that all conditions and limitations the licenses refer to are described
+func Parse(content []byte) (*Catalogue, error) {
+	c := &Catalogue{}
+	if err := yaml.Unmarshal(content, c); err != nil {
+		return nil, fmt.Errorf("failed to parse obligations catalogue: %w", err)
+	}
+
+	for license, terms := range c.Licenses {
+		for _, condition := range terms.Conditions {
+			if _, found := c.Conditions[condition]; !found {
+				return nil, fmt.Errorf("license %s refers to unknown condition '%s'", license, condition)
+			}
+		}
+		for _, limitation := range terms.Limitations {
+			if _, found := c.Limitations[limitation]; !found {
+				return nil, fmt.Errorf("license %s refers to unknown limitation '%s'", license, limitation)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// Terms returns the conditions and limitations of a license. Licenses with
+// an exception, like `GPL-2.0-only-WITH-Classpath-exception-2.0`, get the
+// terms of the license without the exception if there are none for the
+// combination. The second return value is false if the license isn't in the
+// catalogue.
+func (c *Catalogue) Terms(license string) (LicenseTerms, bool) {
+	if terms, found := c.Licenses[license]; found {
+		return terms, true
+	}
+
+	if base, _, found := strings.Cut(license, "-WITH-"); found {
+		terms, found := c.Licenses[base]
+		return terms, found
+	}
+
+	return LicenseTerms{}, false
+}
+
+// Obligations returns the conditions of a license, in the order they're
+// listed in the catalogue. The second return value is false if the license
+// isn't in the catalogue.
+func (c *Catalogue) Obligations(license string) ([]Obligation, bool) {
+	terms, found := c.Terms(license)
+	if !found {
+		return nil, false
+	}
+
+	obligations := make([]Obligation, 0, len(terms.Conditions))
+	for _, condition := range terms.Conditions {
+		obligations = append(obligations, Obligation{
+			ID:          condition,
+			Description: c.Conditions[condition],
+		})
+	}
+	return obligations, true
+}
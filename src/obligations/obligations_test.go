@@ -0,0 +1,68 @@
+package obligations_test
+
+import (
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/obligations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	catalogue, err := obligations.Load()
+	require.NoError(t, err)
+
+	apache, found := catalogue.Obligations("Apache-2.0")
+	require.True(t, found)
+	assert.Equal(t, []string{"include-copyright", "include-notice", "document-changes"}, obligationIDs(apache))
+
+	lgpl, found := catalogue.Obligations("LGPL-3.0-or-later")
+	require.True(t, found)
+	assert.Contains(t, obligationIDs(lgpl), "allow-relinking")
+
+	mpl, found := catalogue.Obligations("MPL-2.0")
+	require.True(t, found)
+	assert.Contains(t, obligationIDs(mpl), "disclose-source--file")
+
+	// licenses without conditions are known, but have no obligations
+	unlicense, found := catalogue.Obligations("Unlicense")
+	assert.True(t, found)
+	assert.Empty(t, unlicense)
+}
+
+func TestCatalogue_Terms(t *testing.T) {
+	catalogue, err := obligations.Load()
+	require.NoError(t, err)
+
+	t.Run("exceptions fall back to the base license", func(t *testing.T) {
+		terms, found := catalogue.Terms("GPL-2.0-only-WITH-Classpath-exception-2.0")
+		require.True(t, found)
+
+		gpl, _ := catalogue.Terms("GPL-2.0-only")
+		assert.Equal(t, gpl, terms)
+	})
+
+	t.Run("unknown license", func(t *testing.T) {
+		_, found := catalogue.Terms("LicenseRef-Bespoke")
+		assert.False(t, found)
+	})
+}
+
+func TestParse_UnknownCondition(t *testing.T) {
+	_, err := obligations.Parse([]byte(`
+conditions:
+  include-copyright: Include the copyright notice
+licenses:
+  MIT:
+    conditions: [include-copyrite]
+`))
+	assert.ErrorContains(t, err, "include-copyrite")
+}
+
+func obligationIDs(obligations []obligations.Obligation) []string {
+	var ids []string
+	for _, obligation := range obligations {
+		ids = append(ids, obligation.ID)
+	}
+	return ids
+}
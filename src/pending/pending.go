@@ -0,0 +1,195 @@
+// Package pending keeps the unknown licenses found in non-interactive runs in
+// a file, where reviewers decide on them. The decisions are then applied to
+// the licenses file with the `decide` command, so that every decision goes
+// through code review.
+package pending
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/eriklarko/license-checker/src/atomicfile"
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+	"gopkg.in/yaml.v3"
+)
+
+// Decision is what a reviewer decided about a pending license
+type Decision string
+
+const (
+	Undecided Decision = ""
+	Allow     Decision = "allow"
+	Deny      Decision = "deny"
+)
+
+// written at the top of the file, as the file is meant to be edited by hand
+const header = `# Licenses no decision has been made for yet.
+#
+# Set the decision of each license to allow or deny and get the change
+# reviewed. Then run ` + "`license-checker decide`" + ` to move the decisions into the
+# licenses file.
+`
+
+// File is the content of the pending decisions file
+type File struct {
+	Licenses []License `yaml:"licenses"`
+}
+
+// License is a license that needs a decision
+type License struct {
+	License string `yaml:"license"`
+	// Filled in by a reviewer
+	Decision  Decision  `yaml:"decision"`
+	FirstSeen time.Time `yaml:"first-seen"`
+	// The dependencies using the license
+	Dependencies []Dependency `yaml:"dependencies"`
+}
+
+// Dependency gives a reviewer context on where a license is used
+type Dependency struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version,omitempty"`
+	// The dependency's whole license expression, which can contain other
+	// licenses than the pending one
+	LicenseExpression string `yaml:"license-expression"`
+	// Where the dependency is declared, as `path` or `path:line`
+	Location string `yaml:"location,omitempty"`
+}
+
+var now = time.Now
+
+// Load reads a pending decisions file. A missing file has no pending
+// licenses.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &File{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read pending decisions %s: %w", path, err)
+	}
+
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("pending decisions %s is not valid yaml: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Update replaces the pending licenses with the unknown licenses in the
+// report. Decisions already made on licenses that are still unknown are kept.
+// Problems in the baseline are left out.
+func (f *File) Update(report *checker.Report, dependencies []collector.Dependency) {
+	previous := make(map[string]License)
+	for _, license := range f.Licenses {
+		previous[license.License] = license
+	}
+
+	byName := make(map[string]collector.Dependency)
+	for _, dependency := range dependencies {
+		byName[dependency.Name] = dependency
+	}
+
+	licenses := make(map[string]*License)
+	for _, decision := range report.SortedDecisions() {
+		if decision.Verdict != checker.VerdictUnknown || report.IsBaselineDebt(decision.Dependency) {
+			continue
+		}
+
+		for _, name := range decision.DecidedBy {
+			license, found := licenses[name]
+			if !found {
+				license = &License{License: name, FirstSeen: now().UTC().Truncate(time.Second)}
+				if p, found := previous[name]; found {
+					license.Decision = p.Decision
+					license.FirstSeen = p.FirstSeen
+				}
+				licenses[name] = license
+			}
+
+			license.Dependencies = append(license.Dependencies, toDependency(decision, byName[decision.Dependency]))
+		}
+	}
+
+	f.Licenses = make([]License, 0, len(licenses))
+	for _, license := range licenses {
+		f.Licenses = append(f.Licenses, *license)
+	}
+	sort.Slice(f.Licenses, func(i, j int) bool {
+		return f.Licenses[i].License < f.Licenses[j].License
+	})
+}
+
+func toDependency(decision checker.Decision, dependency collector.Dependency) Dependency {
+	d := Dependency{
+		Name:              decision.Dependency,
+		Version:           decision.Version,
+		LicenseExpression: decision.License,
+	}
+	if location := dependency.Location; location != nil {
+		d.Location = location.Path
+		if location.Line > 0 {
+			d.Location = fmt.Sprintf("%s:%d", location.Path, location.Line)
+		}
+	}
+	return d
+}
+
+// Decided returns the licenses a decision has been made on
+func (f *File) Decided() ([]License, error) {
+	var decided []License
+	var errs []error
+	for _, license := range f.Licenses {
+		switch license.Decision {
+		case Undecided:
+			continue
+		case Allow, Deny:
+			decided = append(decided, license)
+		default:
+			errs = append(errs, fmt.Errorf("the decision on %s must be %s or %s, but is '%s'", license.License, Allow, Deny, license.Decision))
+		}
+	}
+	return decided, errors.Join(errs...)
+}
+
+// Remove removes a license from the pending licenses
+func (f *File) Remove(license string) {
+	for i := range f.Licenses {
+		if f.Licenses[i].License == license {
+			f.Licenses = append(f.Licenses[:i], f.Licenses[i+1:]...)
+			return
+		}
+	}
+}
+
+// Write writes the pending licenses to a file, or removes the file if there
+// are none
+func (f *File) Write(path string) error {
+	if len(f.Licenses) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove pending decisions %s: %w", path, err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return fmt.Errorf("failed to encode pending decisions as yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode pending decisions as yaml: %w", err)
+	}
+
+	return atomicfile.WriteFile(path, 0644, func(w io.Writer) error {
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+}
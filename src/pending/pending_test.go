@@ -0,0 +1,88 @@
+package pending_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+	"github.com/eriklarko/license-checker/src/pending"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAndWrite(t *testing.T) {
+	dependencies := []collector.Dependency{
+		{Name: "allowed", License: "MIT"},
+		{Name: "dual", Version: "1.0.0", License: "MIT && ISC", Location: &collector.Location{Path: "package-lock.json", Line: 12}},
+		{Name: "isc", License: "ISC"},
+		{Name: "weird", License: "WTFPL"},
+	}
+	path := filepath.Join(t.TempDir(), "pending-decisions.yaml")
+
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateCurrentLicenses(collector.ToLicenseMap(dependencies))
+	require.NoError(t, err)
+	report.RecordVersions(collector.ToVersionMap(dependencies))
+
+	f, err := pending.Load(path)
+	require.NoError(t, err)
+	f.Update(report, dependencies)
+	require.Len(t, f.Licenses, 2)
+	assert.Equal(t, "ISC", f.Licenses[0].License)
+	assert.Equal(t, []pending.Dependency{
+		{Name: "dual", Version: "1.0.0", LicenseExpression: "MIT && ISC", Location: "package-lock.json:12"},
+		{Name: "isc", LicenseExpression: "ISC"},
+	}, f.Licenses[0].Dependencies)
+	assert.Equal(t, "WTFPL", f.Licenses[1].License)
+
+	// a reviewer decides on ISC
+	f.Licenses[0].Decision = pending.Allow
+	require.NoError(t, f.Write(path))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# Licenses no decision has been made for yet.")
+
+	// the decision survives the next run, and licenses that are no longer
+	// used are dropped
+	dependencies = dependencies[:3]
+	report, err = lc.ValidateCurrentLicenses(collector.ToLicenseMap(dependencies))
+	require.NoError(t, err)
+	f, err = pending.Load(path)
+	require.NoError(t, err)
+	f.Update(report, dependencies)
+	require.Len(t, f.Licenses, 1)
+	assert.Equal(t, pending.Allow, f.Licenses[0].Decision)
+	assert.False(t, f.Licenses[0].FirstSeen.IsZero())
+}
+
+func TestDecided(t *testing.T) {
+	f := &pending.File{Licenses: []pending.License{
+		{License: "ISC", Decision: pending.Allow},
+		{License: "WTFPL"},
+		{License: "GPL-3.0-only", Decision: pending.Deny},
+	}}
+
+	decided, err := f.Decided()
+	require.NoError(t, err)
+	require.Len(t, decided, 2)
+	assert.Equal(t, "ISC", decided[0].License)
+	assert.Equal(t, "GPL-3.0-only", decided[1].License)
+
+	f.Licenses = append(f.Licenses, pending.License{License: "BSD-3-Clause", Decision: "yes"})
+	_, err = f.Decided()
+	assert.ErrorContains(t, err, "the decision on BSD-3-Clause must be allow or deny")
+}
+
+func TestWrite_RemovesEmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pending-decisions.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("licenses: []"), 0644))
+
+	f := &pending.File{Licenses: []pending.License{{License: "ISC"}}}
+	f.Remove("ISC")
+	require.NoError(t, f.Write(path))
+
+	assert.NoFileExists(t, path)
+}
@@ -1 +1,15 @@
 package referencedlicense
+
+import (
+	"strings"
+
+	"github.com/eriklarko/license-checker/src/licenseclassifier"
+)
+
+// normalizeLicenseText removes the parts of a license text that change
+// without the license changing; copyright lines, which are updated every new
+// year, and formatting
+func normalizeLicenseText(text string) string {
+	text, _ = licenseclassifier.SplitCopyright(text)
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -1,137 +1,310 @@
 package referencedlicense
 
 import (
-	"crypto/md5"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"sort"
+
+	"github.com/eriklarko/license-checker/src/atomicfile"
 )
 
-type ReferenceValueVerifier struct {
-	outputFile string
+// the first row of the csv store, used to recognize the format
+var header = []string{"dependency", "version", "sha256", "declared-license"}
 
-	knownLicenses map[string]string
+// Verification is the outcome of comparing a dependency's license text to the
+// recorded one
+type Verification int
+
+const (
+	// No license text has been recorded for the dependency
+	Unrecorded Verification = iota
+	// The license text is the same as the recorded one
+	Unchanged
+	// The license text changed, but the declared license is the same. This
+	// is what silent relicensing looks like.
+	TextChanged
+	// The declared license changed. Changes to the text are expected then,
+	// and the new license is checked like any other license.
+	DeclaredLicenseChanged
+)
 
-	outputFileHandle *os.File
-	writer           *csv.Writer
+// Key identifies a version of a dependency. The version is empty if the
+// collector doesn't know it.
+type Key struct {
+	Dependency string
+	Version    string
 }
 
-func NewReferenceValueVerifier(outputFile string) *ReferenceValueVerifier {
-	return &ReferenceValueVerifier{
-		outputFile: outputFile,
-	}
+// Entry is the recorded license text of a version of a dependency
+type Entry struct {
+	Key
+	// SHA-256 of the normalized license text, see HashLicenseText
+	Hash            string
+	DeclaredLicense string
 }
 
-func (r *ReferenceValueVerifier) Record(dependency, license string) error {
-	md5Sum := calculateMD5Sum(license)
-	err := r.appendToLicenseFile(dependency, md5Sum)
-	if err != nil {
-		return fmt.Errorf("failed to append to license file for dependency %s: %w", dependency, err)
+// NewEntry hashes a license text into an entry
+func NewEntry(key Key, declaredLicense, licenseText string) Entry {
+	return Entry{
+		Key:             key,
+		Hash:            HashLicenseText(licenseText),
+		DeclaredLicense: declaredLicense,
 	}
+}
 
-	return nil
+// HashLicenseText returns the hex encoded SHA-256 of a license text, ignoring
+// copyright lines and formatting
+func HashLicenseText(text string) string {
+	hash := sha256.Sum256([]byte(normalizeLicenseText(text)))
+	return hex.EncodeToString(hash[:])
 }
 
-func calculateMD5Sum(license string) string {
-	hash := md5.Sum([]byte(license))
-	md5Sum := fmt.Sprintf("%x", hash)
-	return md5Sum
+// Diff is the difference between the recorded entries and another set of
+// entries
+type Diff struct {
+	// Not recorded
+	Added []Key
+	// Recorded, but not in the other set
+	Removed []Key
+	// Recorded with another hash or declared license
+	Changed []Key
 }
 
-func (r *ReferenceValueVerifier) appendToLicenseFile(dependency, md5Sum string) error {
-	if r.writer == nil {
-		var err error
-		r.writer, err = r.openWriter()
-		if err != nil {
-			return fmt.Errorf("failed to open writer: %w", err)
-		}
+func (d Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// ReferenceValueVerifier remembers the license text of each version of each
+// dependency, to detect license texts that change without the declared
+// license changing.
+//
+// The texts are stored as hashes in a csv file with the columns
+// `dependency,version,sha256,declared-license`. Changes are made in memory
+// and written with Save, which replaces the whole file atomically.
+type ReferenceValueVerifier struct {
+	path string
+
+	// nil until loaded from `path`
+	entries map[Key]Entry
+}
+
+func NewReferenceValueVerifier(path string) *ReferenceValueVerifier {
+	return &ReferenceValueVerifier{
+		path: path,
 	}
+}
 
-	record := []string{dependency, md5Sum}
-	err := r.writer.Write(record)
-	if err != nil {
-		return fmt.Errorf("failed to write record to CSV: %w", err)
+// Verify compares the license text of a dependency to the recorded one. If
+// no text is recorded for the version, it's compared to the texts of the
+// dependency's other versions, so that texts changing in an upgrade are
+// detected too.
+func (r *ReferenceValueVerifier) Verify(key Key, declaredLicense, licenseText string) (Verification, error) {
+	if err := r.load(); err != nil {
+		return Unrecorded, err
 	}
 
-	return nil
+	hash := HashLicenseText(licenseText)
+	if recorded, found := r.entries[key]; found {
+		return compare(recorded, declaredLicense, hash), nil
+	}
+
+	verification := Unrecorded
+	for _, recorded := range r.entries {
+		if recorded.Dependency != key.Dependency {
+			continue
+		}
+
+		// one matching version is enough
+		switch compare(recorded, declaredLicense, hash) {
+		case Unchanged:
+			return Unchanged, nil
+		case TextChanged:
+			verification = TextChanged
+		case DeclaredLicenseChanged:
+			if verification == Unrecorded {
+				verification = DeclaredLicenseChanged
+			}
+		}
+	}
+	return verification, nil
 }
 
-func (r *ReferenceValueVerifier) openWriter() (*csv.Writer, error) {
-	absPath, err := filepath.Abs(r.outputFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get absolute path of output file: %w", err)
+func compare(recorded Entry, declaredLicense, hash string) Verification {
+	switch {
+	case recorded.DeclaredLicense != declaredLicense:
+		return DeclaredLicenseChanged
+	case recorded.Hash != hash:
+		return TextChanged
+	default:
+		return Unchanged
 	}
+}
 
-	file, err := os.OpenFile(absPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open output file: %w", err)
+// Update records an entry, replacing any entry with the same key
+func (r *ReferenceValueVerifier) Update(entry Entry) error {
+	if err := r.load(); err != nil {
+		return err
 	}
-	r.outputFileHandle = file
 
-	writer := csv.NewWriter(file)
-	return writer, nil
+	r.entries[entry.Key] = entry
+	return nil
 }
 
-func (r *ReferenceValueVerifier) Close() error {
-	if r.writer != nil {
-		r.writer.Flush()
-		r.writer = nil
+// Remove forgets the entry with the given key, if there is one
+func (r *ReferenceValueVerifier) Remove(key Key) error {
+	if err := r.load(); err != nil {
+		return err
 	}
 
-	if r.outputFileHandle != nil {
-		err := r.outputFileHandle.Close()
-		if err != nil {
-			return fmt.Errorf("failed to close output file handle: %w", err)
-		}
+	delete(r.entries, key)
+	return nil
+}
+
+// Entries returns all recorded entries, sorted by key
+func (r *ReferenceValueVerifier) Entries() ([]Entry, error) {
+	if err := r.load(); err != nil {
+		return nil, err
 	}
 
-	return nil
+	entries := make([]Entry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sortEntries(entries)
+	return entries, nil
 }
 
-func (r *ReferenceValueVerifier) CheckLicenseMatch(license string) (bool, error) {
-	md5Sum := calculateMD5Sum(license)
+// Diff compares the recorded entries to the given entries
+func (r *ReferenceValueVerifier) Diff(entries []Entry) (Diff, error) {
+	if err := r.load(); err != nil {
+		return Diff{}, err
+	}
+
+	diff := Diff{}
+	current := make(map[Key]bool, len(entries))
+	for _, entry := range entries {
+		current[entry.Key] = true
 
-	if r.knownLicenses == nil {
-		err := r.loadKnownLicenses()
-		if err != nil {
-			return false, fmt.Errorf("failed to load known licenses: %w", err)
+		recorded, found := r.entries[entry.Key]
+		if !found {
+			diff.Added = append(diff.Added, entry.Key)
+		} else if recorded != entry {
+			diff.Changed = append(diff.Changed, entry.Key)
 		}
 	}
-
-	for _, record := range r.knownLicenses {
-		if record == md5Sum {
-			return true, nil
+	for key := range r.entries {
+		if !current[key] {
+			diff.Removed = append(diff.Removed, key)
 		}
 	}
 
-	return false, nil
+	sortKeys(diff.Added)
+	sortKeys(diff.Removed)
+	sortKeys(diff.Changed)
+	return diff, nil
 }
 
-func (r *ReferenceValueVerifier) loadKnownLicenses() error {
-	absPath, err := filepath.Abs(r.outputFile)
+// Save writes all entries to the csv store. The file is replaced atomically,
+// so it's never left half-written.
+func (r *ReferenceValueVerifier) Save() error {
+	entries, err := r.Entries()
 	if err != nil {
-		absPath = r.outputFile
+		return err
 	}
 
-	file, err := os.Open(absPath)
-	if err != nil {
-		return fmt.Errorf("failed to open output file: %w", err)
+	return atomicfile.WriteFile(r.path, 0644, func(w io.Writer) error {
+		writer := csv.NewWriter(w)
+		records := [][]string{header}
+		for _, entry := range entries {
+			records = append(records, []string{entry.Dependency, entry.Version, entry.Hash, entry.DeclaredLicense})
+		}
+		if err := writer.WriteAll(records); err != nil {
+			return fmt.Errorf("failed to write records to CSV: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *ReferenceValueVerifier) load() error {
+	if r.entries != nil {
+		return nil
+	}
+
+	file, err := os.Open(r.path)
+	if os.IsNotExist(err) {
+		// nothing has been recorded yet
+		r.entries = make(map[Key]Entry)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to open %s: %w", r.path, err)
 	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
-		return fmt.Errorf("failed to read records from CSV: %w", err)
+		return fmt.Errorf("failed to read records from %s: %w", r.path, err)
 	}
 
-	r.knownLicenses = make(map[string]string)
-	for _, record := range records {
-		dependency := record[0]
-		md5Sum := record[1]
-		r.knownLicenses[dependency] = md5Sum
+	entries := make(map[Key]Entry)
+	if len(records) > 0 && !isHeader(records[0]) {
+		// the texts of all dependencies will be recorded again
+		slog.Warn("Ignoring license texts recorded in an old format", "path", r.path)
+		r.entries = entries
+		return nil
 	}
 
+	for i, record := range records[min(1, len(records)):] {
+		if len(record) != len(header) {
+			// +2 as line numbers are 1-based and the header was skipped
+			return fmt.Errorf("%s line %d: expected %d columns but got %d", r.path, i+2, len(header), len(record))
+		}
+
+		entry := Entry{
+			Key:             Key{Dependency: record[0], Version: record[1]},
+			Hash:            record[2],
+			DeclaredLicense: record[3],
+		}
+		entries[entry.Key] = entry
+	}
+
+	r.entries = entries
 	return nil
 }
+
+func isHeader(record []string) bool {
+	if len(record) != len(header) {
+		return false
+	}
+	for i := range header {
+		if record[i] != header[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return keyLess(entries[i].Key, entries[j].Key)
+	})
+}
+
+func sortKeys(keys []Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		return keyLess(keys[i], keys[j])
+	})
+}
+
+func keyLess(a, b Key) bool {
+	if a.Dependency != b.Dependency {
+		return a.Dependency < b.Dependency
+	}
+	return a.Version < b.Version
+}
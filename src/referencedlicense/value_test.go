@@ -0,0 +1,168 @@
+package referencedlicense_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/referencedlicense"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mitText = `Copyright (c) 2023 Some Person
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software...`
+
+const buslText = "Business Source License 1.1\n\nYou may not use this software in production..."
+
+var v1 = referencedlicense.Key{Dependency: "some-dependency", Version: "1.0.0"}
+
+func TestReferenceValueVerifier_Verify(t *testing.T) {
+	// the directory is created when the store is saved
+	lockfile := filepath.Join(t.TempDir(), ".license-checker", "license-texts.lock")
+
+	verifier := referencedlicense.NewReferenceValueVerifier(lockfile)
+	verification, err := verifier.Verify(v1, "MIT", mitText)
+	require.NoError(t, err)
+	assert.Equal(t, referencedlicense.Unrecorded, verification)
+
+	require.NoError(t, verifier.Update(referencedlicense.NewEntry(v1, "MIT", mitText)))
+	require.NoError(t, verifier.Save())
+
+	// a new verifier reads what the previous one saved
+	verifier = referencedlicense.NewReferenceValueVerifier(lockfile)
+
+	v2 := referencedlicense.Key{Dependency: "some-dependency", Version: "2.0.0"}
+	tests := map[string]struct {
+		key             referencedlicense.Key
+		declaredLicense string
+		text            string
+		expected        referencedlicense.Verification
+	}{
+		"same text": {
+			key:             v1,
+			declaredLicense: "MIT",
+			text:            mitText,
+			expected:        referencedlicense.Unchanged,
+		},
+		"new copyright year and formatting": {
+			key:             v1,
+			declaredLicense: "MIT",
+			text:            "Copyright (c) 2024 Some Person\n\nPermission is hereby granted, free of charge,\nto any person obtaining a copy of this software...\n",
+			expected:        referencedlicense.Unchanged,
+		},
+		"changed text": {
+			key:             v1,
+			declaredLicense: "MIT",
+			text:            buslText,
+			expected:        referencedlicense.TextChanged,
+		},
+		"changed declared license": {
+			key:             v1,
+			declaredLicense: "BUSL-1.1",
+			text:            buslText,
+			expected:        referencedlicense.DeclaredLicenseChanged,
+		},
+		"new version with the same text": {
+			key:             v2,
+			declaredLicense: "MIT",
+			text:            mitText,
+			expected:        referencedlicense.Unchanged,
+		},
+		"new version with changed text": {
+			key:             v2,
+			declaredLicense: "MIT",
+			text:            buslText,
+			expected:        referencedlicense.TextChanged,
+		},
+		"other dependency": {
+			key:             referencedlicense.Key{Dependency: "other-dependency", Version: "1.0.0"},
+			declaredLicense: "MIT",
+			text:            mitText,
+			expected:        referencedlicense.Unrecorded,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			verification, err := verifier.Verify(tt.key, tt.declaredLicense, tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, verification)
+		})
+	}
+}
+
+func TestReferenceValueVerifier_UpdateAndRemove(t *testing.T) {
+	lockfile := filepath.Join(t.TempDir(), "license-texts.lock")
+	verifier := referencedlicense.NewReferenceValueVerifier(lockfile)
+
+	require.NoError(t, verifier.Update(referencedlicense.NewEntry(v1, "MIT", mitText)))
+	require.NoError(t, verifier.Update(referencedlicense.NewEntry(v1, "BUSL-1.1", buslText)))
+
+	verification, err := verifier.Verify(v1, "BUSL-1.1", buslText)
+	require.NoError(t, err)
+	assert.Equal(t, referencedlicense.Unchanged, verification)
+
+	require.NoError(t, verifier.Remove(v1))
+	verification, err = verifier.Verify(v1, "BUSL-1.1", buslText)
+	require.NoError(t, err)
+	assert.Equal(t, referencedlicense.Unrecorded, verification)
+}
+
+func TestReferenceValueVerifier_Save(t *testing.T) {
+	lockfile := filepath.Join(t.TempDir(), "license-texts.lock")
+	verifier := referencedlicense.NewReferenceValueVerifier(lockfile)
+
+	require.NoError(t, verifier.Update(referencedlicense.NewEntry(referencedlicense.Key{Dependency: "b"}, "MIT", mitText)))
+	require.NoError(t, verifier.Update(referencedlicense.NewEntry(v1, "MIT", mitText)))
+	require.NoError(t, verifier.Save())
+
+	content, err := os.ReadFile(lockfile)
+	require.NoError(t, err)
+	hash := referencedlicense.HashLicenseText(mitText)
+	assert.Equal(t, "dependency,version,sha256,declared-license\n"+
+		"b,,"+hash+",MIT\n"+
+		"some-dependency,1.0.0,"+hash+",MIT\n", string(content))
+
+	// no temporary files are left behind
+	files, err := os.ReadDir(filepath.Dir(lockfile))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestReferenceValueVerifier_Diff(t *testing.T) {
+	verifier := referencedlicense.NewReferenceValueVerifier(filepath.Join(t.TempDir(), "license-texts.lock"))
+
+	unchanged := referencedlicense.Key{Dependency: "unchanged", Version: "1.0.0"}
+	removed := referencedlicense.Key{Dependency: "removed", Version: "1.0.0"}
+	require.NoError(t, verifier.Update(referencedlicense.NewEntry(unchanged, "MIT", mitText)))
+	require.NoError(t, verifier.Update(referencedlicense.NewEntry(removed, "MIT", mitText)))
+	require.NoError(t, verifier.Update(referencedlicense.NewEntry(v1, "MIT", mitText)))
+
+	added := referencedlicense.Key{Dependency: "added", Version: "1.0.0"}
+	diff, err := verifier.Diff([]referencedlicense.Entry{
+		referencedlicense.NewEntry(unchanged, "MIT", mitText),
+		referencedlicense.NewEntry(v1, "MIT", buslText),
+		referencedlicense.NewEntry(added, "MIT", mitText),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, referencedlicense.Diff{
+		Added:   []referencedlicense.Key{added},
+		Removed: []referencedlicense.Key{removed},
+		Changed: []referencedlicense.Key{v1},
+	}, diff)
+}
+
+func TestReferenceValueVerifier_IgnoresOldFiles(t *testing.T) {
+	lockfile := filepath.Join(t.TempDir(), "license-texts.lock")
+	require.NoError(t, os.WriteFile(lockfile, []byte("some-dependency,d41d8cd98f00b204e9800998ecf8427e,MIT\n"), 0644))
+
+	verifier := referencedlicense.NewReferenceValueVerifier(lockfile)
+
+	// md5 hashes can't be compared, so the text is recorded again
+	verification, err := verifier.Verify(v1, "MIT", "")
+	require.NoError(t, err)
+	assert.Equal(t, referencedlicense.Unrecorded, verification)
+}
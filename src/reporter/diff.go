@@ -0,0 +1,136 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	texttemplate "text/template"
+
+	"github.com/eriklarko/license-checker/src/depdiff"
+)
+
+var diffMarkdownTemplate = texttemplate.Must(
+	texttemplate.New("diff.md.tmpl").
+		Funcs(texttemplate.FuncMap{"cell": escapeTableCell}).
+		ParseFS(templates, "templates/diff.md.tmpl"),
+)
+
+// JSONDiff is the json representation of a depdiff.Diff
+type JSONDiff struct {
+	Base           string       `json:"base"`
+	Head           string       `json:"head"`
+	Added          []JSONChange `json:"added"`
+	Removed        []JSONChange `json:"removed"`
+	LicenseChanged []JSONChange `json:"licenseChanged"`
+}
+
+// JSONChange is a dependency that was added, removed or changed license
+type JSONChange struct {
+	Name        string `json:"name"`
+	BaseVersion string `json:"baseVersion,omitempty"`
+	BaseLicense string `json:"baseLicense,omitempty"`
+	HeadVersion string `json:"headVersion,omitempty"`
+	HeadLicense string `json:"headLicense,omitempty"`
+	// The verdict on the head license. Empty for removed dependencies.
+	Verdict string `json:"verdict,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// diffReport is what the diff templates render
+type diffReport struct {
+	Base string
+	Head string
+	*depdiff.Diff
+	Disclaimer string
+}
+
+// WriteDiffJSON writes the diff between two revisions as indented json
+func WriteDiffJSON(w io.Writer, diff *depdiff.Diff, base, head string) error {
+	jsonDiff := JSONDiff{
+		Base:           base,
+		Head:           head,
+		Added:          toJSONChanges(diff.Added),
+		Removed:        toJSONChanges(diff.Removed),
+		LicenseChanged: toJSONChanges(diff.LicenseChanged),
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(jsonDiff); err != nil {
+		return fmt.Errorf("failed to encode json diff: %w", err)
+	}
+	return nil
+}
+
+func toJSONChanges(changes []depdiff.Change) []JSONChange {
+	// never null, to make life easier for consumers
+	jsonChanges := []JSONChange{}
+	for _, change := range changes {
+		jsonChange := JSONChange{
+			Name:        change.Dependency,
+			BaseVersion: change.BaseVersion,
+			BaseLicense: change.BaseLicense,
+			HeadVersion: change.HeadVersion,
+			HeadLicense: change.HeadLicense,
+		}
+		if change.Decision != nil {
+			jsonChange.Verdict = string(change.Decision.Verdict)
+			jsonChange.Reason = change.Decision.Reason
+		}
+		jsonChanges = append(jsonChanges, jsonChange)
+	}
+	return jsonChanges
+}
+
+// WriteDiffMarkdown writes the diff between two revisions as GitHub flavoured
+// Markdown, suitable for pull request comments
+func WriteDiffMarkdown(w io.Writer, diff *depdiff.Diff, base, head string, disclaimer string) error {
+	err := diffMarkdownTemplate.Execute(w, diffReport{Base: base, Head: head, Diff: diff, Disclaimer: disclaimer})
+	if err != nil {
+		return fmt.Errorf("failed to render Markdown diff: %w", err)
+	}
+	return nil
+}
+
+// WriteDiffText writes the diff between two revisions as plain text, one
+// dependency per line
+func WriteDiffText(w io.Writer, diff *depdiff.Diff, base, head string) error {
+	lines := []string{fmt.Sprintf("Dependency changes from %s to %s", base, head)}
+	if diff.IsEmpty() {
+		lines = append(lines, "  No dependencies were added, removed or changed license")
+	}
+	for _, change := range diff.Added {
+		lines = append(lines, fmt.Sprintf("+ %s: %s", withVersion(change.Dependency, change.HeadVersion), withVerdict(change.HeadLicense, change)))
+	}
+	for _, change := range diff.Removed {
+		lines = append(lines, fmt.Sprintf("- %s: %s", withVersion(change.Dependency, change.BaseVersion), change.BaseLicense))
+	}
+	for _, change := range diff.LicenseChanged {
+		lines = append(lines, fmt.Sprintf("~ %s: %s -> %s",
+			change.Dependency,
+			withVersion(change.BaseLicense, change.BaseVersion),
+			withVerdict(withVersion(change.HeadLicense, change.HeadVersion), change),
+		))
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+	}
+	return nil
+}
+
+func withVersion(s string, version string) string {
+	if version == "" {
+		return s
+	}
+	return fmt.Sprintf("%s (%s)", s, version)
+}
+
+func withVerdict(s string, change depdiff.Change) string {
+	if change.Decision == nil {
+		return s
+	}
+	return fmt.Sprintf("%s [%s]", s, change.Decision.Verdict)
+}
@@ -0,0 +1,68 @@
+package reporter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+	"github.com/eriklarko/license-checker/src/depdiff"
+	"github.com/eriklarko/license-checker/src/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDiff(t *testing.T) {
+	diff := depdiff.Compare(
+		[]collector.Dependency{
+			{Name: "relicensed", Version: "1.0.0", License: "MIT"},
+			{Name: "removed", Version: "1.0.0", License: "ISC"},
+		},
+		[]collector.Dependency{
+			{Name: "relicensed", Version: "2.0.0", License: "BUSL-1.1"},
+			{Name: "added", Version: "0.1.0", License: "MIT || Apache-2.0"},
+		},
+	)
+	require.NoError(t, diff.Check(checker.NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"BUSL-1.1"})))
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, reporter.WriteDiffText(&buf, diff, "main", "HEAD"))
+
+		assert.Equal(t, `Dependency changes from main to HEAD
++ added (0.1.0): MIT || Apache-2.0 [allowed]
+- removed (1.0.0): ISC
+~ relicensed: MIT (1.0.0) -> BUSL-1.1 (2.0.0) [disallowed]
+`, buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, reporter.WriteDiffJSON(&buf, diff, "main", "HEAD"))
+
+		var jsonDiff reporter.JSONDiff
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonDiff))
+
+		assert.Equal(t, "main", jsonDiff.Base)
+		assert.Equal(t, []reporter.JSONChange{{
+			Name:        "added",
+			HeadVersion: "0.1.0",
+			HeadLicense: "MIT || Apache-2.0",
+			Verdict:     "allowed",
+			Reason:      "allowed by the decisions for MIT, Apache-2.0",
+		}}, jsonDiff.Added)
+		assert.Equal(t, []reporter.JSONChange{{Name: "removed", BaseVersion: "1.0.0", BaseLicense: "ISC"}}, jsonDiff.Removed)
+		assert.Equal(t, "disallowed", jsonDiff.LicenseChanged[0].Verdict)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, reporter.WriteDiffMarkdown(&buf, diff, "main", "HEAD", "NOT LEGAL ADVICE"))
+
+		assert.Contains(t, buf.String(), "| added | 0.1.0 | `MIT \\|\\| Apache-2.0` | allowed |")
+		assert.Contains(t, buf.String(), "| relicensed | `MIT` (1.0.0) | `BUSL-1.1` (2.0.0) | disallowed |")
+		assert.Contains(t, buf.String(), "| removed | 1.0.0 | `ISC` |")
+		assert.NotContains(t, buf.String(), "No dependencies were added")
+	})
+}
@@ -0,0 +1,158 @@
+package reporter
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/eriklarko/license-checker/src/checker"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+var markdownTemplate = texttemplate.Must(
+	texttemplate.New("report.md.tmpl").
+		Funcs(texttemplate.FuncMap{"cell": escapeTableCell}).
+		ParseFS(templates, "templates/report.md.tmpl"),
+)
+var htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templates, "templates/report.html.tmpl"))
+
+// humanReport is what the Markdown and HTML templates render
+type humanReport struct {
+	Title      string
+	Disclaimer string
+	Summary    JSONSummary
+	Sections   []verdictSection
+	// Dependencies whose license text changed although their declared
+	// license didn't
+	LicenseTextChanges []JSONLicenseTextChange
+	// Dependencies whose license changed since the last check
+	Relicensed []checker.Relicensing
+	// Nil if no baseline is used
+	Baseline *checker.BaselineStatus
+	// The dependencies whose problems are in the baseline
+	Baselined map[string]bool
+	// The obligations of the allowed dependencies' licenses
+	Obligations []checker.TriggeredObligation
+	// The outcome per project, when several projects are checked
+	Projects []projectSummary
+}
+
+// projectSummary is the outcome of checking a single project
+type projectSummary struct {
+	Path    string
+	Summary JSONSummary
+	// The disallowed and unknown dependencies of the project
+	Problems []checker.Decision
+}
+
+// verdictSection lists all licenses with the same verdict
+type verdictSection struct {
+	Verdict  checker.Verdict
+	Title    string
+	Licenses []licenseGroup
+}
+
+// licenseGroup lists all dependencies using the same license
+type licenseGroup struct {
+	License      string
+	Dependencies []checker.Decision
+}
+
+// WriteMarkdown writes a summary of the report as GitHub flavoured Markdown,
+// suitable for pull request comments. The dependencies of each license are
+// listed in collapsible sections.
+func WriteMarkdown(w io.Writer, report *checker.Report, disclaimer string) error {
+	if err := markdownTemplate.Execute(w, newHumanReport(report, disclaimer)); err != nil {
+		return fmt.Errorf("failed to render Markdown report: %w", err)
+	}
+	return nil
+}
+
+// WriteHTML writes a summary of the report as a self-contained HTML page
+func WriteHTML(w io.Writer, report *checker.Report, disclaimer string) error {
+	if err := htmlTemplate.Execute(w, newHumanReport(report, disclaimer)); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+func newHumanReport(report *checker.Report, disclaimer string) humanReport {
+	summary, _ := toJSONDependencies(report, report.IsBaselineDebt)
+
+	// the most pressing problems first
+	sections := []verdictSection{
+		{Verdict: checker.VerdictDisallowed, Title: "Disallowed licenses"},
+		{Verdict: checker.VerdictUnknown, Title: "Unknown licenses"},
+		{Verdict: checker.VerdictAllowed, Title: "Allowed licenses"},
+	}
+	for i := range sections {
+		sections[i].Licenses = groupByLicense(report, sections[i].Verdict)
+	}
+
+	human := humanReport{
+		Title:      "License report",
+		Disclaimer: disclaimer,
+		Summary:    summary,
+		Sections:   sections,
+		Relicensed: report.SortedRelicensings(),
+		Baseline:   report.Baseline,
+		Baselined:  make(map[string]bool),
+
+		Obligations:        report.SortedObligations(),
+		LicenseTextChanges: toJSONLicenseTextChanges(report),
+	}
+	if report.Baseline != nil {
+		for dependency := range report.Baseline.Debt {
+			human.Baselined[dependency] = true
+		}
+	}
+	for _, project := range report.ProjectNames() {
+		projectReport := report.Projects[project]
+		projectSummary := projectSummary{Path: project}
+		projectSummary.Summary, _ = toJSONDependencies(projectReport, report.IsBaselineDebt)
+		for _, decision := range projectReport.SortedDecisions() {
+			if decision.Verdict != checker.VerdictAllowed {
+				projectSummary.Problems = append(projectSummary.Problems, decision)
+			}
+		}
+		human.Projects = append(human.Projects, projectSummary)
+	}
+	return human
+}
+
+func groupByLicense(report *checker.Report, verdict checker.Verdict) []licenseGroup {
+	groups := make(map[string]*licenseGroup)
+	for _, decision := range report.SortedDecisions() {
+		if decision.Verdict != verdict {
+			continue
+		}
+
+		group, found := groups[decision.License]
+		if !found {
+			group = &licenseGroup{License: decision.License}
+			groups[decision.License] = group
+		}
+		group.Dependencies = append(group.Dependencies, decision)
+	}
+
+	licenses := make([]licenseGroup, 0, len(groups))
+	for _, group := range groups {
+		licenses = append(licenses, *group)
+	}
+	sort.Slice(licenses, func(i, j int) bool {
+		return licenses[i].License < licenses[j].License
+	})
+	return licenses
+}
+
+// escapeTableCell escapes the pipes in license expressions, which would
+// otherwise end Markdown table cells, even inside code spans
+func escapeTableCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
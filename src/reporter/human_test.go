@@ -0,0 +1,258 @@
+package reporter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/obligations"
+	"github.com/eriklarko/license-checker/src/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":    "MIT || Apache-2.0",
+		"disallowed-dep": "GPL-3.0-only",
+		"unknown-dep":    "ISC",
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteMarkdown(&buf, report, "NOT LEGAL ADVICE"))
+
+	assert.Equal(t, `# License report
+
+| Verdict | Dependencies |
+| --- | ---: |
+| :x: Disallowed | 1 |
+| :question: Unknown | 1 |
+| :white_check_mark: Allowed | 1 |
+
+## Disallowed licenses
+
+| License | Dependencies |
+| --- | ---: |
+| `+"`GPL-3.0-only`"+` | 1 |
+
+<details>
+<summary><code>GPL-3.0-only</code> (1)</summary>
+
+- **disallowed-dep**: disallowed by the decisions for GPL-3.0-only
+
+</details>
+
+## Unknown licenses
+
+| License | Dependencies |
+| --- | ---: |
+| `+"`ISC`"+` | 1 |
+
+<details>
+<summary><code>ISC</code> (1)</summary>
+
+- **unknown-dep**: no decision has been made for ISC
+
+</details>
+
+## Allowed licenses
+
+| License | Dependencies |
+| --- | ---: |
+| `+"`MIT \\|\\| Apache-2.0`"+` | 1 |
+
+<details>
+<summary><code>MIT || Apache-2.0</code> (1)</summary>
+
+- **allowed-dep**: allowed by the decisions for MIT, Apache-2.0
+
+</details>
+
+> NOT LEGAL ADVICE
+`, buf.String())
+}
+
+func TestWriteMarkdown_OnlyAllowed(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"dep": "MIT"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteMarkdown(&buf, report, "NOT LEGAL ADVICE"))
+
+	assert.NotContains(t, buf.String(), "## Disallowed licenses")
+	assert.NotContains(t, buf.String(), "## Unknown licenses")
+	assert.Contains(t, buf.String(), "## Allowed licenses")
+}
+
+func TestWriteHTML(t *testing.T) {
+	lc := checker.NewFromLists(nil, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"<script>": "MIT"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteHTML(&buf, report, "NOT LEGAL ADVICE"))
+
+	html := buf.String()
+	assert.Contains(t, html, `<h2 class="unknown">Unknown licenses</h2>`)
+	assert.Contains(t, html, `<summary><code>MIT</code> (1)</summary>`)
+	assert.Contains(t, html, `<p class="disclaimer">NOT LEGAL ADVICE</p>`)
+	// dependency names are escaped
+	assert.Contains(t, html, "<strong>&lt;script&gt;</strong>")
+	// the page is self-contained
+	assert.NotContains(t, html, "<link")
+	assert.NotContains(t, html, "src=")
+}
+
+func TestWriteMarkdown_Obligations(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"allowed-dep": "MIT || Apache-2.0"})
+	require.NoError(t, err)
+	report.RecordObligation(obligations.Obligation{ID: "include-notice", Description: "Propagate the NOTICE file"}, "Apache-2.0", "allowed-dep")
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteMarkdown(&buf, report, "NOT LEGAL ADVICE"))
+
+	assert.Contains(t, buf.String(), `## Obligations
+
+| Obligation | Licenses | Dependencies |
+| --- | --- | ---: |
+| Propagate the NOTICE file | `+"`Apache-2.0`"+` | 1 |
+
+<details>
+<summary>Propagate the NOTICE file (1)</summary>
+
+- allowed-dep
+
+</details>
+`)
+}
+
+func TestWriteMarkdown_Relicensed(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"allowed-dep": "MIT || Apache-2.0"})
+	require.NoError(t, err)
+	report.RecordVersions(map[string]string{"allowed-dep": "2.0.0"})
+	report.RecordRelicensing(checker.Relicensing{
+		Dependency:      "allowed-dep",
+		PreviousVersion: "1.0.0",
+		PreviousLicense: "MIT",
+		Version:         "2.0.0",
+		License:         "MIT || Apache-2.0",
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteMarkdown(&buf, report, "NOT LEGAL ADVICE"))
+
+	assert.Contains(t, buf.String(), `## Relicensed dependencies
+
+| Dependency | Before | After |
+| --- | --- | --- |
+| allowed-dep | `+"`MIT`"+` (1.0.0) | `+"`MIT \\|\\| Apache-2.0`"+` (2.0.0) |
+`)
+	assert.Contains(t, buf.String(), "- **allowed-dep** 2.0.0: allowed by")
+}
+
+func TestWriteMarkdown_Baseline(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":    "MIT || Apache-2.0",
+		"disallowed-dep": "GPL-3.0-only",
+		"unknown-dep":    "ISC",
+	})
+	require.NoError(t, err)
+	report.ApplyBaseline([]checker.BaselineEntry{
+		{Dependency: "disallowed-dep", License: "GPL-3.0-only", Verdict: checker.VerdictDisallowed},
+		{Dependency: "removed-dep", License: "ISC", Verdict: checker.VerdictUnknown},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteMarkdown(&buf, report, "NOT LEGAL ADVICE"))
+
+	assert.Contains(t, buf.String(), `## Baseline debt
+
+1 problems in the baseline remain and 1 have been fixed.
+`)
+	assert.Contains(t, buf.String(), "- **removed-dep**: `ISC` was unknown")
+	assert.Contains(t, buf.String(), "- **disallowed-dep**: disallowed by the decisions for GPL-3.0-only (in the baseline)")
+	assert.NotContains(t, buf.String(), "ISC (in the baseline)")
+}
+
+func TestWriteMarkdown_Projects(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateProjects(map[string]map[string]string{
+		"frontend": {"react": "MIT"},
+		"backend":  {"gin": "MIT", "mystery": "ISC"},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteMarkdown(&buf, report, "NOT LEGAL ADVICE"))
+
+	assert.Contains(t, buf.String(), `## Projects
+
+| Project | Disallowed | Unknown | Allowed |
+| --- | ---: | ---: | ---: |
+| backend | 0 | 1 | 1 |
+| frontend | 0 | 0 | 1 |
+
+<details>
+<summary>Problems in backend (1)</summary>
+
+- **mystery**: no decision has been made for ISC
+
+</details>
+`)
+}
+
+func TestWriteHTML_Projects(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateProjects(map[string]map[string]string{
+		"frontend": {"react": "MIT"},
+		"backend":  {"mystery": "ISC"},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteHTML(&buf, report, "NOT LEGAL ADVICE"))
+
+	html := buf.String()
+	assert.Contains(t, html, "<h2>Projects</h2>")
+	assert.Contains(t, html, `<tr><td>backend</td><td class="count">0</td><td class="count">1</td><td class="count">0</td></tr>`)
+	assert.Contains(t, html, "<summary>Problems in backend (1)</summary>")
+	assert.NotContains(t, html, "Problems in frontend")
+}
+
+func TestWriteMarkdown_LicenseTextChanges(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"left-pad": "MIT"})
+	require.NoError(t, err)
+	report.RecordChangedLicenseText("left-pad", "MIT")
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteMarkdown(&buf, report, "NOT LEGAL ADVICE"))
+
+	assert.Contains(t, buf.String(), `## Changed license texts
+
+The license texts of these dependencies changed although their declared licenses didn't. They need to be reviewed again.
+
+| Dependency | License |
+| --- | --- |
+| left-pad | `+"`MIT`"+` |
+`)
+}
+
+func TestWriteHTML_LicenseTextChanges(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"left-pad": "MIT"})
+	require.NoError(t, err)
+	report.RecordChangedLicenseText("left-pad", "MIT")
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteHTML(&buf, report, "NOT LEGAL ADVICE"))
+
+	assert.Contains(t, buf.String(), `<h2 class="disallowed">Changed license texts</h2>`)
+	assert.Contains(t, buf.String(), "<tr><td>left-pad</td><td><code>MIT</code></td></tr>")
+}
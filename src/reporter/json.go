@@ -0,0 +1,226 @@
+package reporter
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/samber/lo"
+)
+
+// JSONSchemaVersion is the version of the json report's schema. The minor
+// version is bumped when fields are added, and the major version when fields
+// are removed or change meaning.
+const JSONSchemaVersion = "1.4"
+
+// JSONSchema is the JSON Schema describing the json report
+//
+//go:embed schema/report.schema.json
+var JSONSchema []byte
+
+// JSONReport is the json representation of a checker.Report
+type JSONReport struct {
+	SchemaVersion string           `json:"schemaVersion"`
+	Summary       JSONSummary      `json:"summary"`
+	Dependencies  []JSONDependency `json:"dependencies"`
+	Obligations   []JSONObligation `json:"obligations,omitempty"`
+	// Added in schema version 1.2
+	LicenseTextChanges []JSONLicenseTextChange `json:"licenseTextChanges,omitempty"`
+	// Added in schema version 1.3
+	Relicensed []JSONRelicensing `json:"relicensed,omitempty"`
+	// Added in schema version 1.4
+	Baseline *JSONBaseline `json:"baseline,omitempty"`
+	Projects []JSONProject `json:"projects,omitempty"`
+}
+
+// JSONSummary counts the dependencies per verdict
+type JSONSummary struct {
+	Allowed    int `json:"allowed"`
+	Disallowed int `json:"disallowed"`
+	Unknown    int `json:"unknown"`
+}
+
+// JSONDependency is the verdict on a single dependency
+type JSONDependency struct {
+	Name string `json:"name"`
+	// Empty if the collector doesn't know the version. Added in schema
+	// version 1.3.
+	Version   string   `json:"version,omitempty"`
+	License   string   `json:"license"`
+	Verdict   string   `json:"verdict"`
+	Reason    string   `json:"reason"`
+	DecidedBy []string `json:"decidedBy"`
+	// Where the decisions the verdict is based on are stored. Empty for
+	// unknown verdicts.
+	Source string `json:"source,omitempty"`
+	// True if the problem is tolerated because it's in the baseline. Added
+	// in schema version 1.4.
+	Baselined bool `json:"baselined,omitempty"`
+}
+
+// JSONObligation is an obligation that comes with the licenses of the allowed
+// dependencies. Added in schema version 1.1.
+type JSONObligation struct {
+	ID           string   `json:"id"`
+	Description  string   `json:"description"`
+	Licenses     []string `json:"licenses"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// JSONLicenseTextChange is a dependency whose license text changed although
+// its declared license didn't. It needs to be reviewed again.
+type JSONLicenseTextChange struct {
+	Name    string `json:"name"`
+	License string `json:"license"`
+}
+
+// JSONRelicensing is a dependency whose license changed since the last time
+// it was checked
+type JSONRelicensing struct {
+	Name            string `json:"name"`
+	PreviousVersion string `json:"previousVersion"`
+	PreviousLicense string `json:"previousLicense"`
+	Version         string `json:"version"`
+	License         string `json:"license"`
+}
+
+// JSONBaseline describes how much of the baseline is left
+type JSONBaseline struct {
+	// The problems in the baseline that are still there
+	Remaining int `json:"remaining"`
+	// The problems in the baseline that are gone
+	Fixed []JSONBaselineEntry `json:"fixed"`
+}
+
+// JSONBaselineEntry is a problem in the baseline
+type JSONBaselineEntry struct {
+	Name    string `json:"name"`
+	License string `json:"license"`
+	Verdict string `json:"verdict"`
+}
+
+// JSONProject is the report of a single project when several projects are
+// checked in one run
+type JSONProject struct {
+	Path         string           `json:"path"`
+	Summary      JSONSummary      `json:"summary"`
+	Dependencies []JSONDependency `json:"dependencies"`
+}
+
+// NewJSONReport converts the report into its json representation
+func NewJSONReport(report *checker.Report) *JSONReport {
+	summary, dependencies := toJSONDependencies(report, report.IsBaselineDebt)
+	jsonReport := &JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		Summary:       summary,
+		Dependencies:  dependencies,
+	}
+
+	for _, obligation := range report.SortedObligations() {
+		jsonReport.Obligations = append(jsonReport.Obligations, JSONObligation(obligation))
+	}
+
+	jsonReport.LicenseTextChanges = toJSONLicenseTextChanges(report)
+
+	for _, relicensing := range report.SortedRelicensings() {
+		jsonReport.Relicensed = append(jsonReport.Relicensed, JSONRelicensing{
+			Name:            relicensing.Dependency,
+			PreviousVersion: relicensing.PreviousVersion,
+			PreviousLicense: relicensing.PreviousLicense,
+			Version:         relicensing.Version,
+			License:         relicensing.License,
+		})
+	}
+
+	if report.Baseline != nil {
+		jsonReport.Baseline = &JSONBaseline{
+			Remaining: len(report.Baseline.Debt),
+			Fixed:     []JSONBaselineEntry{},
+		}
+		for _, entry := range report.Baseline.Fixed {
+			jsonReport.Baseline.Fixed = append(jsonReport.Baseline.Fixed, JSONBaselineEntry{
+				Name:    entry.Dependency,
+				License: entry.License,
+				Verdict: string(entry.Verdict),
+			})
+		}
+	}
+
+	for _, project := range report.ProjectNames() {
+		summary, dependencies := toJSONDependencies(report.Projects[project], report.IsBaselineDebt)
+		jsonReport.Projects = append(jsonReport.Projects, JSONProject{
+			Path:         project,
+			Summary:      summary,
+			Dependencies: dependencies,
+		})
+	}
+
+	return jsonReport
+}
+
+// toJSONLicenseTextChanges lists the dependencies whose license text changed,
+// sorted by dependency
+func toJSONLicenseTextChanges(report *checker.Report) []JSONLicenseTextChange {
+	var changes []JSONLicenseTextChange
+	for _, dependency := range lo.Keys(report.ChangedLicenseTexts) {
+		changes = append(changes, JSONLicenseTextChange{
+			Name:    dependency,
+			License: report.ChangedLicenseTexts[dependency],
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// toJSONDependencies converts the decisions in a report. isBaselineDebt is
+// passed separately, as only the top-level report knows the baseline.
+func toJSONDependencies(report *checker.Report, isBaselineDebt func(dependency string) bool) (JSONSummary, []JSONDependency) {
+	summary := JSONSummary{}
+	// never null, to make life easier for consumers
+	dependencies := []JSONDependency{}
+
+	for _, decision := range report.SortedDecisions() {
+		switch decision.Verdict {
+		case checker.VerdictAllowed:
+			summary.Allowed++
+		case checker.VerdictDisallowed:
+			summary.Disallowed++
+		default:
+			summary.Unknown++
+		}
+
+		decidedBy := decision.DecidedBy
+		if decidedBy == nil {
+			decidedBy = []string{}
+		}
+
+		dependencies = append(dependencies, JSONDependency{
+			Name:      decision.Dependency,
+			Version:   decision.Version,
+			License:   decision.License,
+			Verdict:   string(decision.Verdict),
+			Reason:    decision.Reason,
+			DecidedBy: decidedBy,
+			Source:    decision.Source,
+			Baselined: isBaselineDebt(decision.Dependency),
+		})
+	}
+
+	return summary, dependencies
+}
+
+// WriteJSON writes the report as indented json
+func WriteJSON(w io.Writer, report *checker.Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(NewJSONReport(report)); err != nil {
+		return fmt.Errorf("failed to encode json report: %w", err)
+	}
+
+	return nil
+}
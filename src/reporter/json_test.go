@@ -0,0 +1,208 @@
+package reporter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/obligations"
+	"github.com/eriklarko/license-checker/src/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":    "MIT || Apache-2.0",
+		"disallowed-dep": "GPL-3.0-only",
+		"unknown-dep":    "ISC",
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJSON(&buf, report))
+
+	var jsonReport reporter.JSONReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonReport))
+
+	assert.Equal(t, reporter.JSONSchemaVersion, jsonReport.SchemaVersion)
+	assert.Equal(t, reporter.JSONSummary{Allowed: 1, Disallowed: 1, Unknown: 1}, jsonReport.Summary)
+	assert.Equal(t, []reporter.JSONDependency{
+		{
+			Name:      "allowed-dep",
+			License:   "MIT || Apache-2.0",
+			Verdict:   "allowed",
+			Reason:    "allowed by the decisions for MIT, Apache-2.0",
+			DecidedBy: []string{"MIT", "Apache-2.0"},
+		},
+		{
+			Name:      "disallowed-dep",
+			License:   "GPL-3.0-only",
+			Verdict:   "disallowed",
+			Reason:    "disallowed by the decisions for GPL-3.0-only",
+			DecidedBy: []string{"GPL-3.0-only"},
+		},
+		{
+			Name:      "unknown-dep",
+			License:   "ISC",
+			Verdict:   "unknown",
+			Reason:    "no decision has been made for ISC",
+			DecidedBy: []string{"ISC"},
+		},
+	}, jsonReport.Dependencies)
+	assert.Empty(t, jsonReport.Projects)
+}
+
+func TestWriteJSON_Projects(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateProjects(map[string]map[string]string{
+		"frontend": {"react": "MIT"},
+		"backend":  {"gin": "MIT", "mystery": "ISC"},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJSON(&buf, report))
+
+	var jsonReport reporter.JSONReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonReport))
+
+	assert.Equal(t, reporter.JSONSummary{Allowed: 2, Unknown: 1}, jsonReport.Summary)
+	require.Len(t, jsonReport.Projects, 2)
+	assert.Equal(t, "backend", jsonReport.Projects[0].Path)
+	assert.Equal(t, reporter.JSONSummary{Allowed: 1, Unknown: 1}, jsonReport.Projects[0].Summary)
+	assert.Equal(t, "frontend", jsonReport.Projects[1].Path)
+	assert.Len(t, jsonReport.Projects[1].Dependencies, 1)
+}
+
+func TestWriteJSON_EmptyReport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJSON(&buf, &checker.Report{}))
+
+	// consumers should never have to deal with null lists
+	assert.Contains(t, buf.String(), `"dependencies": []`)
+}
+
+func TestJSONSchema(t *testing.T) {
+	var schema struct {
+		Required []string `json:"required"`
+		Defs     map[string]struct {
+			Required []string `json:"required"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(reporter.JSONSchema, &schema))
+
+	// every field the schema requires is written
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"allowed-dep": "MIT || Apache-2.0"})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJSON(&buf, report))
+
+	var jsonReport map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonReport))
+	for _, field := range schema.Required {
+		assert.Contains(t, jsonReport, field)
+	}
+
+	dependency := jsonReport["dependencies"].([]any)[0].(map[string]any)
+	for _, field := range schema.Defs["dependency"].Required {
+		assert.Contains(t, dependency, field)
+	}
+}
+
+func TestWriteJSON_Obligations(t *testing.T) {
+	catalogue, err := obligations.Load()
+	require.NoError(t, err)
+
+	lc := checker.NewFromLists([]string{"Apache-2.0"}, nil)
+	lc.Obligations = catalogue
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"some-dep": "Apache-2.0"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJSON(&buf, report))
+
+	var jsonReport reporter.JSONReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonReport))
+
+	ids := make([]string, 0, len(jsonReport.Obligations))
+	for _, obligation := range jsonReport.Obligations {
+		ids = append(ids, obligation.ID)
+		assert.Equal(t, []string{"Apache-2.0"}, obligation.Licenses)
+		assert.Equal(t, []string{"some-dep"}, obligation.Dependencies)
+		assert.NotEmpty(t, obligation.Description)
+	}
+	assert.Equal(t, []string{"document-changes", "include-copyright", "include-notice"}, ids)
+}
+
+func TestWriteJSON_LicenseTextChanges(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"allowed-dep": "MIT || Apache-2.0"})
+	require.NoError(t, err)
+	report.RecordChangedLicenseText("allowed-dep", "MIT || Apache-2.0")
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJSON(&buf, report))
+
+	var jsonReport reporter.JSONReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonReport))
+
+	assert.Equal(t, []reporter.JSONLicenseTextChange{
+		{Name: "allowed-dep", License: "MIT || Apache-2.0"},
+	}, jsonReport.LicenseTextChanges)
+}
+
+func TestWriteJSON_Relicensed(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"allowed-dep": "MIT || Apache-2.0"})
+	require.NoError(t, err)
+	report.RecordVersions(map[string]string{"allowed-dep": "2.0.0"})
+	report.RecordRelicensing(checker.Relicensing{
+		Dependency:      "allowed-dep",
+		PreviousVersion: "1.0.0",
+		PreviousLicense: "MIT",
+		Version:         "2.0.0",
+		License:         "MIT || Apache-2.0",
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJSON(&buf, report))
+
+	var jsonReport reporter.JSONReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonReport))
+
+	assert.Equal(t, "2.0.0", jsonReport.Dependencies[0].Version)
+	assert.Equal(t, []reporter.JSONRelicensing{
+		{Name: "allowed-dep", PreviousVersion: "1.0.0", PreviousLicense: "MIT", Version: "2.0.0", License: "MIT || Apache-2.0"},
+	}, jsonReport.Relicensed)
+}
+
+func TestWriteJSON_Baseline(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":    "MIT || Apache-2.0",
+		"disallowed-dep": "GPL-3.0-only",
+		"unknown-dep":    "ISC",
+	})
+	require.NoError(t, err)
+	report.ApplyBaseline([]checker.BaselineEntry{
+		{Dependency: "disallowed-dep", License: "GPL-3.0-only", Verdict: checker.VerdictDisallowed},
+		{Dependency: "removed-dep", License: "ISC", Verdict: checker.VerdictUnknown},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJSON(&buf, report))
+
+	var jsonReport reporter.JSONReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonReport))
+
+	assert.Equal(t, &reporter.JSONBaseline{
+		Remaining: 1,
+		Fixed:     []reporter.JSONBaselineEntry{{Name: "removed-dep", License: "ISC", Verdict: "unknown"}},
+	}, jsonReport.Baseline)
+	assert.True(t, jsonReport.Dependencies[1].Baselined)
+	assert.False(t, jsonReport.Dependencies[2].Baselined)
+}
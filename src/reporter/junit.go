@@ -0,0 +1,159 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/eriklarko/license-checker/src/checker"
+)
+
+// junitTestSuites is the JUnit XML format understood by most CI servers. There
+// is no formal specification, but see
+// https://github.com/testmoapp/junitxml for the common denominator.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML, with one test case per
+// dependency and one test suite per license. Allowed dependencies pass,
+// disallowed dependencies fail and unknown dependencies are skipped.
+// Disallowed dependencies in the baseline are skipped too, as they don't fail
+// the check. When several projects are checked, each project gets its own
+// test suites, named `project: license`. Dependencies whose license text
+// changed although their declared license didn't fail in a test suite of
+// their own, as they need to be reviewed again.
+func WriteJUnit(w io.Writer, report *checker.Report) error {
+	var suites []junitTestSuite
+	if len(report.Projects) == 0 {
+		suites = toJUnitTestSuites(report, report.IsBaselineDebt, "")
+	}
+	for _, project := range report.ProjectNames() {
+		suites = append(suites, toJUnitTestSuites(report.Projects[project], report.IsBaselineDebt, project+": ")...)
+	}
+	if changes := toJSONLicenseTextChanges(report); len(changes) > 0 {
+		suites = append(suites, toJUnitLicenseTextChanges(changes))
+	}
+
+	testSuites := junitTestSuites{Name: toolName}
+	for _, suite := range suites {
+		testSuites.Tests += suite.Tests
+		testSuites.Failures += suite.Failures
+		testSuites.Skipped += suite.Skipped
+		testSuites.Suites = append(testSuites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(testSuites); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	return nil
+}
+
+// toJUnitTestSuites groups the dependencies of a report into one test suite
+// per license, sorted by license. The names of the suites start with prefix.
+func toJUnitTestSuites(report *checker.Report, isBaselineDebt func(dependency string) bool, prefix string) []junitTestSuite {
+	suites := make(map[string]*junitTestSuite)
+	for _, decision := range report.SortedDecisions() {
+		suite, found := suites[decision.License]
+		if !found {
+			suite = &junitTestSuite{Name: prefix + decision.License}
+			suites[decision.License] = suite
+		}
+
+		testCase := junitTestCase{
+			Name:      decision.Dependency,
+			ClassName: prefix + decision.License,
+			SystemOut: decision.Reason,
+		}
+		switch decision.Verdict {
+		case checker.VerdictAllowed:
+			// passing test cases have no result element
+		case checker.VerdictDisallowed:
+			if isBaselineDebt(decision.Dependency) {
+				testCase.Skipped = &junitSkipped{Message: "in the baseline: " + decision.Reason}
+				suite.Skipped++
+				break
+			}
+			testCase.Failure = &junitFailure{Message: decision.Reason, Type: string(decision.Verdict)}
+			suite.Failures++
+		default:
+			testCase.Skipped = &junitSkipped{Message: decision.Reason}
+			suite.Skipped++
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	licenses := make([]string, 0, len(suites))
+	for license := range suites {
+		licenses = append(licenses, license)
+	}
+	sort.Strings(licenses)
+
+	sorted := make([]junitTestSuite, 0, len(suites))
+	for _, license := range licenses {
+		sorted = append(sorted, *suites[license])
+	}
+	return sorted
+}
+
+// the name of the test suite with the dependencies whose license text changed
+const changedLicenseTextsSuite = "changed license texts"
+
+// toJUnitLicenseTextChanges turns the dependencies whose license text changed
+// into a test suite with one failing test case per dependency
+func toJUnitLicenseTextChanges(changes []JSONLicenseTextChange) junitTestSuite {
+	suite := junitTestSuite{Name: changedLicenseTextsSuite}
+	for _, change := range changes {
+		message := fmt.Sprintf("the license text changed although the declared license, %s, didn't. It needs to be reviewed again.", change.License)
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      change.Name,
+			ClassName: changedLicenseTextsSuite,
+			Failure:   &junitFailure{Message: message, Type: changedLicenseTextRule},
+		})
+		suite.Tests++
+		suite.Failures++
+	}
+	return suite
+}
@@ -0,0 +1,108 @@
+package reporter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-1":      "MIT",
+		"allowed-2":      "MIT",
+		"disallowed-dep": "GPL-3.0-only",
+		"unknown-dep":    "ISC",
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJUnit(&buf, report))
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="license-checker" tests="4" failures="1" skipped="1">
+  <testsuite name="GPL-3.0-only" tests="1" failures="1" skipped="0">
+    <testcase name="disallowed-dep" classname="GPL-3.0-only">
+      <failure message="disallowed by the decisions for GPL-3.0-only" type="disallowed"></failure>
+      <system-out>disallowed by the decisions for GPL-3.0-only</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="ISC" tests="1" failures="0" skipped="1">
+    <testcase name="unknown-dep" classname="ISC">
+      <skipped message="no decision has been made for ISC"></skipped>
+      <system-out>no decision has been made for ISC</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="MIT" tests="2" failures="0" skipped="0">
+    <testcase name="allowed-1" classname="MIT">
+      <system-out>allowed by the decisions for MIT</system-out>
+    </testcase>
+    <testcase name="allowed-2" classname="MIT">
+      <system-out>allowed by the decisions for MIT</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestWriteJUnit_Projects(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateProjects(map[string]map[string]string{
+		"frontend": {"react": "MIT"},
+		"backend":  {"gin": "MIT", "mystery": "ISC"},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJUnit(&buf, report))
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="license-checker" tests="3" failures="0" skipped="1">
+  <testsuite name="backend: ISC" tests="1" failures="0" skipped="1">
+    <testcase name="mystery" classname="backend: ISC">
+      <skipped message="no decision has been made for ISC"></skipped>
+      <system-out>no decision has been made for ISC</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="backend: MIT" tests="1" failures="0" skipped="0">
+    <testcase name="gin" classname="backend: MIT">
+      <system-out>allowed by the decisions for MIT</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="frontend: MIT" tests="1" failures="0" skipped="0">
+    <testcase name="react" classname="frontend: MIT">
+      <system-out>allowed by the decisions for MIT</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestWriteJUnit_LicenseTextChanges(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"left-pad": "MIT"})
+	require.NoError(t, err)
+	report.RecordChangedLicenseText("left-pad", "MIT")
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteJUnit(&buf, report))
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="license-checker" tests="2" failures="1" skipped="0">
+  <testsuite name="MIT" tests="1" failures="0" skipped="0">
+    <testcase name="left-pad" classname="MIT">
+      <system-out>allowed by the decisions for MIT</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="changed license texts" tests="1" failures="1" skipped="0">
+    <testcase name="left-pad" classname="changed license texts">
+      <failure message="the license text changed although the declared license, MIT, didn&#39;t. It needs to be reviewed again." type="changed-license-text"></failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
@@ -0,0 +1,254 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// the name this tool uses when taking credit for a report
+const toolName = "license-checker"
+
+// the rule of the dependencies whose license text changed although their
+// declared license didn't
+const changedLicenseTextRule = "changed-license-text"
+
+// sarifLog is the subset of a SARIF 2.1.0 log this tool writes. See
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+	// "new" or "unchanged" if a baseline is used
+	BaselineState string           `json:"baselineState,omitempty"`
+	Properties    *sarifProperties `json:"properties,omitempty"`
+}
+
+type sarifProperties struct {
+	// The project the dependency is used in, when several projects are
+	// checked
+	Project string `json:"project,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes one SARIF result per disallowed or unknown license of each
+// dependency. Each license gets its own rule, so that code scanning tools can
+// group and dismiss findings per license, also when it's part of a larger
+// license expression. Disallowed licenses are errors and unknown
+// licenses are warnings. If a baseline is used, problems in the baseline are
+// marked as unchanged. When several projects are checked, each project's
+// problems are reported separately, with the project in the result's
+// properties. Dependencies whose license text changed although their declared
+// license didn't are errors too, as they need to be reviewed again.
+//
+// locations holds where each dependency is declared, keyed by dependency.
+// Dependencies without a known location are reported without one. Relative
+// paths in the log are relative to baseDir.
+func WriteSARIF(w io.Writer, report *checker.Report, locations map[string]collector.Location, baseDir string) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{Name: toolName, Rules: []sarifRule{}},
+		},
+		Results: []sarifResult{},
+	}
+
+	// project -> the project's report, or the whole report keyed by "" if
+	// there's only one project
+	projectReports := map[string]*checker.Report{"": report}
+	projects := []string{""}
+	if len(report.Projects) > 0 {
+		projectReports = report.Projects
+		projects = report.ProjectNames()
+	}
+
+	ruleIndices := make(map[string]int)
+	for _, project := range projects {
+		for _, decision := range projectReports[project].SortedDecisions() {
+			if decision.Verdict == checker.VerdictAllowed {
+				continue
+			}
+			// e.g. both licenses of `GPL-3.0-only || AGPL-3.0-only` are to
+			// blame if they're both denied
+			licenses := decision.DecidedBy
+			if len(licenses) == 0 {
+				licenses = []string{decision.License}
+			}
+			for _, license := range licenses {
+				run.Results = append(run.Results, toSARIFResult(&run, ruleIndices, report, project, decision, license, locations, baseDir))
+			}
+		}
+	}
+
+	for _, change := range toJSONLicenseTextChanges(report) {
+		ruleIndex := addSARIFRule(&run, ruleIndices, changedLicenseTextRule, "License text changed without the declared license changing", "error")
+		result := sarifResult{
+			RuleID:    changedLicenseTextRule,
+			RuleIndex: ruleIndex,
+			Level:     "error",
+			Message: sarifMessage{Text: fmt.Sprintf(
+				"The license text of dependency %s changed although its declared license, %s, didn't. It needs to be reviewed again.",
+				change.Name, change.License,
+			)},
+		}
+		if location, found := locations[change.Name]; found {
+			result.Locations = []sarifLocation{toSARIFLocation(location, baseDir)}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode SARIF log: %w", err)
+	}
+
+	return nil
+}
+
+// toSARIFResult converts the verdict on one of the licenses of a dependency to
+// a SARIF result, adding the rule of the verdict to the run if it isn't there
+// yet
+func toSARIFResult(
+	run *sarifRun,
+	ruleIndices map[string]int,
+	report *checker.Report,
+	project string,
+	decision checker.Decision,
+	license string,
+	locations map[string]collector.Location,
+	baseDir string,
+) sarifResult {
+	level := sarifLevel(decision.Verdict)
+	ruleID := fmt.Sprintf("%s-license/%s", decision.Verdict, license)
+	ruleIndex := addSARIFRule(run, ruleIndices, ruleID, fmt.Sprintf("License %s is %s", license, decision.Verdict), level)
+
+	message := fmt.Sprintf("Dependency %s uses license %s, which is %s: %s", decision.Dependency, license, decision.Verdict, decision.Reason)
+	if license != decision.License {
+		message = fmt.Sprintf("Dependency %s is licensed under %s, and %s is %s: %s", decision.Dependency, decision.License, license, decision.Verdict, decision.Reason)
+	}
+	result := sarifResult{
+		RuleID:    ruleID,
+		RuleIndex: ruleIndex,
+		Level:     level,
+		Message:   sarifMessage{Text: message},
+	}
+	if report.Baseline != nil {
+		result.BaselineState = "new"
+		if report.IsBaselineDebt(decision.Dependency) {
+			result.BaselineState = "unchanged"
+		}
+	}
+	if location, found := locations[decision.Dependency]; found {
+		result.Locations = []sarifLocation{toSARIFLocation(location, baseDir)}
+	}
+	if project != "" {
+		result.Properties = &sarifProperties{Project: project}
+	}
+
+	return result
+}
+
+// addSARIFRule adds a rule to the run if it isn't there yet, and returns its
+// index
+func addSARIFRule(run *sarifRun, ruleIndices map[string]int, ruleID string, description string, level string) int {
+	if ruleIndex, found := ruleIndices[ruleID]; found {
+		return ruleIndex
+	}
+
+	ruleIndex := len(run.Tool.Driver.Rules)
+	ruleIndices[ruleID] = ruleIndex
+	run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+		ID:                   ruleID,
+		ShortDescription:     sarifMessage{Text: description},
+		DefaultConfiguration: sarifConfiguration{Level: level},
+	})
+	return ruleIndex
+}
+
+func sarifLevel(verdict checker.Verdict) string {
+	if verdict == checker.VerdictDisallowed {
+		return "error"
+	}
+	return "warning"
+}
+
+func toSARIFLocation(location collector.Location, baseDir string) sarifLocation {
+	path := location.Path
+	if filepath.IsAbs(path) && baseDir != "" {
+		if rel, err := filepath.Rel(baseDir, path); err == nil {
+			path = rel
+		}
+	}
+
+	physicalLocation := sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(path)},
+	}
+	if location.Line > 0 {
+		physicalLocation.Region = &sarifRegion{StartLine: location.Line}
+	}
+
+	return sarifLocation{PhysicalLocation: physicalLocation}
+}
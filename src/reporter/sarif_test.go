@@ -0,0 +1,201 @@
+package reporter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/collector"
+	"github.com/eriklarko/license-checker/src/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":    "MIT || Apache-2.0",
+		"disallowed-dep": "GPL-3.0-only",
+		"unknown-dep":    "ISC",
+	})
+	require.NoError(t, err)
+
+	locations := map[string]collector.Location{
+		"disallowed-dep": {Path: "/project/package-lock.json", Line: 42},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteSARIF(&buf, report, locations, "/project"))
+
+	var log map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log["version"])
+
+	expected := `{
+		"tool": {
+			"driver": {
+				"name": "license-checker",
+				"rules": [
+					{
+						"id": "disallowed-license/GPL-3.0-only",
+						"shortDescription": {"text": "License GPL-3.0-only is disallowed"},
+						"defaultConfiguration": {"level": "error"}
+					},
+					{
+						"id": "unknown-license/ISC",
+						"shortDescription": {"text": "License ISC is unknown"},
+						"defaultConfiguration": {"level": "warning"}
+					}
+				]
+			}
+		},
+		"results": [
+			{
+				"ruleId": "disallowed-license/GPL-3.0-only",
+				"ruleIndex": 0,
+				"level": "error",
+				"message": {"text": "Dependency disallowed-dep uses license GPL-3.0-only, which is disallowed: disallowed by the decisions for GPL-3.0-only"},
+				"locations": [
+					{
+						"physicalLocation": {
+							"artifactLocation": {"uri": "package-lock.json"},
+							"region": {"startLine": 42}
+						}
+					}
+				]
+			},
+			{
+				"ruleId": "unknown-license/ISC",
+				"ruleIndex": 1,
+				"level": "warning",
+				"message": {"text": "Dependency unknown-dep uses license ISC, which is unknown: no decision has been made for ISC"}
+			}
+		]
+	}`
+	run, err := json.Marshal(log["runs"].([]any)[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(run))
+}
+
+func TestWriteSARIF_Projects(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateProjects(map[string]map[string]string{
+		"frontend": {"react": "MIT", "mystery": "ISC"},
+		"backend":  {"mystery": "ISC"},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteSARIF(&buf, report, nil, ""))
+
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				RuleID     string            `json:"ruleId"`
+				Properties map[string]string `json:"properties"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+	results := log.Runs[0].Results
+	require.Len(t, results, 2)
+	assert.Equal(t, "unknown-license/ISC", results[0].RuleID)
+	assert.Equal(t, map[string]string{"project": "backend"}, results[0].Properties)
+	assert.Equal(t, "unknown-license/ISC", results[1].RuleID)
+	assert.Equal(t, map[string]string{"project": "frontend"}, results[1].Properties)
+}
+
+func TestWriteSARIF_RulePerLicense(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, []string{"GPL-3.0-only", "AGPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"gpl-dep":      "GPL-3.0-only",
+		"combined-dep": "MIT && GPL-3.0-only",
+		"either-dep":   "GPL-3.0-only || AGPL-3.0-only",
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteSARIF(&buf, report, nil, ""))
+
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID  string `json:"ruleId"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	run := log.Runs[0]
+
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Equal(t, "disallowed-license/GPL-3.0-only", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "disallowed-license/AGPL-3.0-only", run.Tool.Driver.Rules[1].ID)
+
+	ruleIDs := make([]string, 0, len(run.Results))
+	for _, result := range run.Results {
+		ruleIDs = append(ruleIDs, result.RuleID)
+	}
+	assert.Equal(t, []string{
+		// combined-dep
+		"disallowed-license/GPL-3.0-only",
+		// either-dep
+		"disallowed-license/GPL-3.0-only",
+		"disallowed-license/AGPL-3.0-only",
+		// gpl-dep
+		"disallowed-license/GPL-3.0-only",
+	}, ruleIDs)
+	assert.Equal(t, "Dependency combined-dep is licensed under MIT && GPL-3.0-only, and GPL-3.0-only is disallowed: disallowed by the decisions for GPL-3.0-only", run.Results[0].Message.Text)
+}
+
+func TestWriteSARIF_LicenseTextChanges(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT"}, nil)
+	report, err := lc.ValidateCurrentLicenses(map[string]string{"left-pad": "MIT"})
+	require.NoError(t, err)
+	report.RecordChangedLicenseText("left-pad", "MIT")
+
+	locations := map[string]collector.Location{"left-pad": {Path: "/repo/package-lock.json", Line: 7}}
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteSARIF(&buf, report, locations, "/repo"))
+
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID  string `json:"ruleId"`
+				Level   string `json:"level"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []json.RawMessage `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	run := log.Runs[0]
+
+	require.Len(t, run.Tool.Driver.Rules, 1)
+	assert.Equal(t, "changed-license-text", run.Tool.Driver.Rules[0].ID)
+	require.Len(t, run.Results, 1)
+	assert.Equal(t, "changed-license-text", run.Results[0].RuleID)
+	assert.Equal(t, "error", run.Results[0].Level)
+	assert.Equal(t, "The license text of dependency left-pad changed although its declared license, MIT, didn't. It needs to be reviewed again.", run.Results[0].Message.Text)
+	assert.Len(t, run.Results[0].Locations, 1)
+}
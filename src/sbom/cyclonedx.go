@@ -0,0 +1,163 @@
+package sbom
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// cycloneDXBOM is the subset of a CycloneDX 1.5 document this tool cares
+// about. The same structs are used for the json and xml flavours. See
+// https://cyclonedx.org/docs/1.5/json/
+type cycloneDXBOM struct {
+	XMLName      xml.Name             `json:"-" xml:"bom"`
+	BOMFormat    string               `json:"bomFormat" xml:"-"`
+	SpecVersion  string               `json:"specVersion" xml:"-"`
+	SerialNumber string               `json:"serialNumber,omitempty" xml:"-"`
+	Version      int                  `json:"version,omitempty" xml:"-"`
+	Metadata     *cycloneDXMetadata   `json:"metadata,omitempty" xml:"-"`
+	Components   []cycloneDXComponent `json:"components,omitempty" xml:"components>component"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string              `json:"timestamp,omitempty"`
+	Tools     *cycloneDXTools     `json:"tools,omitempty"`
+	Component *cycloneDXComponent `json:"component,omitempty"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type       string               `json:"type" xml:"type,attr"`
+	BOMRef     string               `json:"bom-ref,omitempty" xml:"bom-ref,attr,omitempty"`
+	Group      string               `json:"group,omitempty" xml:"group,omitempty"`
+	Name       string               `json:"name" xml:"name"`
+	Version    string               `json:"version,omitempty" xml:"version,omitempty"`
+	Licenses   cycloneDXLicenses    `json:"licenses,omitempty" xml:"licenses"`
+	Properties []cycloneDXProperty  `json:"properties,omitempty" xml:"-"`
+	Components []cycloneDXComponent `json:"components,omitempty" xml:"components>component"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// cycloneDXLicenses is a list of licenses or license expressions. The json
+// and xml flavours disagree on the shape, so they're normalized into this
+// one.
+type cycloneDXLicenses []cycloneDXLicenseChoice
+
+type cycloneDXLicenseChoice struct {
+	License    *cycloneDXLicense `json:"license,omitempty"`
+	Expression string            `json:"expression,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	ID   string `json:"id,omitempty" xml:"id,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+}
+
+// UnmarshalXML reads the xml flavour of the license list, where licenses and
+// expressions are sibling elements:
+//
+//	<licenses>
+//	  <license><id>MIT</id></license>
+//	  <expression>Apache-2.0 OR MIT</expression>
+//	</licenses>
+func (l *cycloneDXLicenses) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var raw struct {
+		Licenses    []cycloneDXLicense `xml:"license"`
+		Expressions []string           `xml:"expression"`
+	}
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+
+	for i := range raw.Licenses {
+		*l = append(*l, cycloneDXLicenseChoice{License: &raw.Licenses[i]})
+	}
+	for _, expression := range raw.Expressions {
+		*l = append(*l, cycloneDXLicenseChoice{Expression: expression})
+	}
+	return nil
+}
+
+func readCycloneDXJSON(content []byte) ([]Package, error) {
+	var bom cycloneDXBOM
+	if err := json.Unmarshal(content, &bom); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX json: %w", err)
+	}
+
+	return bom.toPackages(), nil
+}
+
+func readCycloneDXXML(content []byte) ([]Package, error) {
+	var bom cycloneDXBOM
+	if err := xml.Unmarshal(content, &bom); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX xml: %w", err)
+	}
+
+	return bom.toPackages(), nil
+}
+
+// toPackages returns all components in the BOM, including nested ones. The
+// component in the metadata, i.e. the project itself, is not included.
+func (b *cycloneDXBOM) toPackages() []Package {
+	var packages []Package
+
+	var visit func(components []cycloneDXComponent)
+	visit = func(components []cycloneDXComponent) {
+		for _, component := range components {
+			name := component.Name
+			if component.Group != "" {
+				name = component.Group + "/" + component.Name
+			}
+
+			packages = append(packages, Package{
+				Name:    name,
+				Version: component.Version,
+				License: component.Licenses.expression(),
+			})
+
+			visit(component.Components)
+		}
+	}
+	visit(b.Components)
+
+	return packages
+}
+
+// expression combines all licenses in the list into a single SPDX license
+// expression. CycloneDX doesn't say how several licenses relate to each other,
+// so to be on the safe side they're all assumed to apply.
+func (l cycloneDXLicenses) expression() string {
+	var parts []string
+	for _, choice := range l {
+		switch {
+		case isMeaningful(choice.Expression):
+			parts = append(parts, strings.TrimSpace(choice.Expression))
+		case choice.License != nil && isMeaningful(choice.License.ID):
+			parts = append(parts, strings.TrimSpace(choice.License.ID))
+		case choice.License != nil && isMeaningful(choice.License.Name):
+			parts = append(parts, toLicenseRef(choice.License.Name))
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return NoAssertion
+	case 1:
+		return parts[0]
+	default:
+		for i, part := range parts {
+			if strings.Contains(part, " ") {
+				parts[i] = "(" + part + ")"
+			}
+		}
+		return strings.Join(parts, " AND ")
+	}
+}
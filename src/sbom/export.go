@@ -0,0 +1,187 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/eriklarko/license-checker/src/checker"
+)
+
+// the name this tool uses when taking credit for an SBOM
+const toolName = "license-checker"
+
+// Names of the CycloneDX properties the checker's verdicts are stored in
+const (
+	PropertyVerdict        = "license-checker:verdict"
+	PropertyReason         = "license-checker:reason"
+	PropertyDecidedBy      = "license-checker:decided-by"
+	PropertyDecisionSource = "license-checker:decision-source"
+)
+
+// now is overridden in tests to get stable output
+var now = time.Now
+
+// Write writes an SBOM listing every dependency in the report, with the
+// dependency's license and the checker's verdict on it. In SPDX documents the
+// verdict is recorded as a REVIEW annotation on each package, and in
+// CycloneDX documents as properties on each component.
+//
+// Supported formats are SPDXJSON and CycloneDXJSON.
+func Write(w io.Writer, format Format, projectName string, report *checker.Report) error {
+	var document any
+	switch format {
+	case SPDXJSON:
+		document = newSPDXDocument(projectName, report)
+	case CycloneDXJSON:
+		document = newCycloneDXBOM(projectName, report)
+	default:
+		return fmt.Errorf("writing SBOMs in format '%s' is not supported", format)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("failed to encode SBOM: %w", err)
+	}
+
+	return nil
+}
+
+func newSPDXDocument(projectName string, report *checker.Report) *spdxDocument {
+	created := now().UTC().Format(time.RFC3339)
+	filesAnalyzed := false
+
+	rootID := "SPDXRef-Package-" + toSPDXIDString(projectName)
+	document := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              projectName,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", toSPDXIDString(projectName), newUUID()),
+		CreationInfo: &spdxCreationInfo{
+			Created:  created,
+			Creators: []string{"Tool: " + toolName},
+		},
+		Packages: []spdxPackage{
+			{
+				SPDXID:           rootID,
+				Name:             projectName,
+				DownloadLocation: NoAssertion,
+				FilesAnalyzed:    &filesAnalyzed,
+			},
+		},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: rootID},
+		},
+	}
+
+	for i, decision := range report.SortedDecisions() {
+		id := fmt.Sprintf("SPDXRef-Package-%s-%d", toSPDXIDString(decision.Dependency), i)
+		license := FromCheckerExpression(decision.License)
+
+		document.Packages = append(document.Packages, spdxPackage{
+			SPDXID:           id,
+			Name:             decision.Dependency,
+			VersionInfo:      decision.Version,
+			DownloadLocation: NoAssertion,
+			FilesAnalyzed:    &filesAnalyzed,
+			LicenseConcluded: license,
+			LicenseDeclared:  license,
+			Annotations: []spdxAnnotation{
+				{
+					AnnotationDate: created,
+					AnnotationType: "REVIEW",
+					Annotator:      "Tool: " + toolName,
+					Comment:        verdictComment(decision),
+				},
+			},
+		})
+		document.Relationships = append(document.Relationships, spdxRelationship{
+			SPDXElementID:      rootID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	return document
+}
+
+// verdictComment describes the verdict in the free text comment of an SPDX
+// annotation
+func verdictComment(decision checker.Decision) string {
+	lines := []string{
+		fmt.Sprintf("%s: %s", PropertyVerdict, decision.Verdict),
+		fmt.Sprintf("%s: %s", PropertyReason, decision.Reason),
+		fmt.Sprintf("%s: %s", PropertyDecidedBy, strings.Join(decision.DecidedBy, ", ")),
+	}
+	if decision.Source != "" {
+		lines = append(lines, fmt.Sprintf("%s: %s", PropertyDecisionSource, decision.Source))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func newCycloneDXBOM(projectName string, report *checker.Report) *cycloneDXBOM {
+	bom := &cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: &cycloneDXMetadata{
+			Timestamp: now().UTC().Format(time.RFC3339),
+			Tools: &cycloneDXTools{
+				Components: []cycloneDXComponent{{Type: "application", Name: toolName}},
+			},
+			Component: &cycloneDXComponent{Type: "application", Name: projectName},
+		},
+	}
+
+	for _, decision := range report.SortedDecisions() {
+		component := cycloneDXComponent{
+			Type:    "library",
+			BOMRef:  decision.Dependency,
+			Name:    decision.Dependency,
+			Version: decision.Version,
+			Properties: []cycloneDXProperty{
+				{Name: PropertyVerdict, Value: string(decision.Verdict)},
+				{Name: PropertyReason, Value: decision.Reason},
+				{Name: PropertyDecidedBy, Value: strings.Join(decision.DecidedBy, ", ")},
+			},
+		}
+		if decision.Source != "" {
+			component.Properties = append(component.Properties, cycloneDXProperty{
+				Name:  PropertyDecisionSource,
+				Value: decision.Source,
+			})
+		}
+
+		license := FromCheckerExpression(decision.License)
+		if license != NoAssertion {
+			component.Licenses = cycloneDXLicenses{{Expression: license}}
+		}
+
+		bom.Components = append(bom.Components, component)
+	}
+
+	return bom
+}
+
+// toSPDXIDString replaces all characters that aren't allowed in SPDX IDs
+func toSPDXIDString(s string) string {
+	return strings.Trim(licenseRefRegex.ReplaceAllString(s, "-"), "-")
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,117 @@
+package sbom_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/checker"
+	"github.com/eriklarko/license-checker/src/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite_SPDX(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":    "MIT || Apache-2.0",
+		"disallowed-dep": "GPL-3.0-only",
+		"unknown-dep":    "NOASSERTION",
+	})
+	require.NoError(t, err)
+	report.RecordVersions(map[string]string{"allowed-dep": "1.0.0"})
+
+	var buf bytes.Buffer
+	require.NoError(t, sbom.Write(&buf, sbom.SPDXJSON, "my-project", report))
+
+	// it can be read back, without the project itself
+	packages, err := sbom.Read(buf.Bytes(), sbom.SPDXJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []sbom.Package{
+		{Name: "allowed-dep", Version: "1.0.0", License: "MIT OR Apache-2.0"},
+		{Name: "disallowed-dep", License: "GPL-3.0-only"},
+		{Name: "unknown-dep", License: sbom.NoAssertion},
+	}, packages)
+
+	// and the verdicts are recorded as annotations
+	var document struct {
+		SPDXVersion string `json:"spdxVersion"`
+		Packages    []struct {
+			Name        string `json:"name"`
+			Annotations []struct {
+				AnnotationType string `json:"annotationType"`
+				Comment        string `json:"comment"`
+			} `json:"annotations"`
+		} `json:"packages"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &document))
+	assert.Equal(t, "SPDX-2.3", document.SPDXVersion)
+
+	comments := make(map[string]string)
+	for _, p := range document.Packages {
+		for _, annotation := range p.Annotations {
+			assert.Equal(t, "REVIEW", annotation.AnnotationType)
+			comments[p.Name] = annotation.Comment
+		}
+	}
+	assert.Contains(t, comments["allowed-dep"], "license-checker:verdict: allowed")
+	assert.Contains(t, comments["allowed-dep"], "license-checker:decided-by: MIT, Apache-2.0")
+	assert.Contains(t, comments["disallowed-dep"], "license-checker:verdict: disallowed")
+	assert.Contains(t, comments["unknown-dep"], "license-checker:verdict: unknown")
+}
+
+func TestWrite_CycloneDX(t *testing.T) {
+	lc := checker.NewFromLists([]string{"MIT", "Apache-2.0"}, []string{"GPL-3.0-only"})
+	report, err := lc.ValidateCurrentLicenses(map[string]string{
+		"allowed-dep":    "MIT || Apache-2.0",
+		"disallowed-dep": "GPL-3.0-only",
+		"unknown-dep":    "NOASSERTION",
+	})
+	require.NoError(t, err)
+	report.RecordVersions(map[string]string{"allowed-dep": "1.0.0"})
+
+	var buf bytes.Buffer
+	require.NoError(t, sbom.Write(&buf, sbom.CycloneDXJSON, "my-project", report))
+
+	format, err := sbom.DetectFormat(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, sbom.CycloneDXJSON, format)
+
+	packages, err := sbom.Read(buf.Bytes(), sbom.CycloneDXJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []sbom.Package{
+		{Name: "allowed-dep", Version: "1.0.0", License: "MIT OR Apache-2.0"},
+		{Name: "disallowed-dep", License: "GPL-3.0-only"},
+		{Name: "unknown-dep", License: sbom.NoAssertion},
+	}, packages)
+
+	var bom struct {
+		Components []struct {
+			Name       string `json:"name"`
+			Properties []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"properties"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &bom))
+
+	verdicts := make(map[string]string)
+	for _, component := range bom.Components {
+		for _, property := range component.Properties {
+			if property.Name == sbom.PropertyVerdict {
+				verdicts[component.Name] = property.Value
+			}
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"allowed-dep":    "allowed",
+		"disallowed-dep": "disallowed",
+		"unknown-dep":    "unknown",
+	}, verdicts)
+}
+
+func TestWrite_UnsupportedFormat(t *testing.T) {
+	err := sbom.Write(&bytes.Buffer{}, sbom.CycloneDXXML, "my-project", &checker.Report{})
+	assert.Error(t, err)
+}
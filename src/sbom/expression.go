@@ -0,0 +1,101 @@
+package sbom
+
+import (
+	"regexp"
+	"strings"
+)
+
+// NoAssertion is what SPDX documents use when a license is unknown
+const NoAssertion = "NOASSERTION"
+
+// WithSeparator joins a license and its exception into a single license, as
+// the checker has no concept of exceptions
+const WithSeparator = "-WITH-"
+
+var expressionTokenRegex = regexp.MustCompile(`\(|\)|[^\s()]+`)
+
+// ToCheckerExpression converts an SPDX license expression, like
+// `MIT OR (Apache-2.0 AND BSD-3-Clause)`, to the syntax the checker
+// understands, like `MIT || (Apache-2.0 && BSD-3-Clause)`.
+//
+// Licenses with exceptions, like `GPL-2.0-only WITH Classpath-exception-2.0`,
+// are joined into a single license, `GPL-2.0-only-WITH-Classpath-exception-2.0`,
+// so that they can be allowed or disallowed separately from the license
+// without the exception.
+func ToCheckerExpression(spdxExpression string) string {
+	tokens := expressionTokenRegex.FindAllString(spdxExpression, -1)
+
+	var converted []string
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch strings.ToUpper(token) {
+		case "AND":
+			converted = append(converted, "&&")
+		case "OR":
+			converted = append(converted, "||")
+		case "WITH":
+			if len(converted) > 0 && i+1 < len(tokens) {
+				converted[len(converted)-1] += WithSeparator + tokens[i+1]
+				i++
+			}
+		default:
+			converted = append(converted, token)
+		}
+	}
+
+	return joinExpressionTokens(converted)
+}
+
+// FromCheckerExpression converts an expression in the checker's syntax back to
+// an SPDX license expression. It's the inverse of ToCheckerExpression.
+func FromCheckerExpression(expression string) string {
+	tokens := expressionTokenRegex.FindAllString(expression, -1)
+
+	converted := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		switch token {
+		case "&&":
+			converted = append(converted, "AND")
+		case "||":
+			converted = append(converted, "OR")
+		default:
+			converted = append(converted, strings.Replace(token, WithSeparator, " WITH ", 1))
+		}
+	}
+
+	return joinExpressionTokens(converted)
+}
+
+// joinExpressionTokens joins tokens with spaces, keeping parentheses next to
+// what they wrap, e.g. `(MIT || ISC) && BSD-3-Clause`
+func joinExpressionTokens(tokens []string) string {
+	var sb strings.Builder
+	for i, token := range tokens {
+		if i > 0 && token != ")" && tokens[i-1] != "(" {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(token)
+	}
+	return sb.String()
+}
+
+// licenseRefRegex matches characters that can't be part of an SPDX license
+// reference
+var licenseRefRegex = regexp.MustCompile(`[^A-Za-z0-9.\-]+`)
+
+// toLicenseRef turns the free text name of a license that has no SPDX ID into
+// a custom SPDX license reference, e.g. `LicenseRef-Some-Custom-License`
+func toLicenseRef(name string) string {
+	sanitized := strings.Trim(licenseRefRegex.ReplaceAllString(name, "-"), "-")
+	if sanitized == "" {
+		return NoAssertion
+	}
+	return "LicenseRef-" + sanitized
+}
+
+// isMeaningful returns false for license fields that don't say anything about
+// the license
+func isMeaningful(license string) bool {
+	license = strings.TrimSpace(license)
+	return license != "" && license != NoAssertion
+}
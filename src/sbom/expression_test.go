@@ -0,0 +1,35 @@
+package sbom_test
+
+import (
+	"testing"
+
+	"github.com/eriklarko/license-checker/src/sbom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToCheckerExpression(t *testing.T) {
+	tests := map[string]string{
+		"MIT":                           "MIT",
+		"MIT OR Apache-2.0":             "MIT || Apache-2.0",
+		"MIT and ISC":                   "MIT && ISC",
+		"(MIT OR ISC) AND BSD-3-Clause": "(MIT || ISC) && BSD-3-Clause",
+		"BSD-3-Clause AND (MIT OR ISC)": "BSD-3-Clause && (MIT || ISC)",
+		"GPL-2.0-only WITH Classpath-exception-2.0":          "GPL-2.0-only-WITH-Classpath-exception-2.0",
+		"MIT OR (GPL-2.0-only WITH Classpath-exception-2.0)": "MIT || (GPL-2.0-only-WITH-Classpath-exception-2.0)",
+	}
+
+	for spdxExpression, expected := range tests {
+		t.Run(spdxExpression, func(t *testing.T) {
+			converted := sbom.ToCheckerExpression(spdxExpression)
+			assert.Equal(t, expected, converted)
+
+			// and back again
+			assert.Equal(t, sbom.ToCheckerExpression(sbom.FromCheckerExpression(converted)), converted)
+		})
+	}
+}
+
+func TestFromCheckerExpression(t *testing.T) {
+	assert.Equal(t, "(MIT OR ISC) AND BSD-3-Clause", sbom.FromCheckerExpression("(MIT || ISC) && BSD-3-Clause"))
+	assert.Equal(t, "GPL-2.0-only WITH Classpath-exception-2.0", sbom.FromCheckerExpression("GPL-2.0-only-WITH-Classpath-exception-2.0"))
+}
@@ -0,0 +1,100 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Format is a supported SBOM document format
+type Format string
+
+const (
+	SPDXJSON      Format = "spdx-json"
+	SPDXTagValue  Format = "spdx-tag-value"
+	CycloneDXJSON Format = "cyclonedx-json"
+	CycloneDXXML  Format = "cyclonedx-xml"
+)
+
+// Package is a software component listed in an SBOM
+type Package struct {
+	Name    string
+	Version string
+	// SPDX license expression, like `MIT OR Apache-2.0`. NOASSERTION if the
+	// SBOM doesn't say what license the package has.
+	License string
+}
+
+// ReadFile reads the packages listed in an SPDX or CycloneDX document. The
+// format of the document is detected from its contents. The package the SBOM
+// describes, i.e. the project itself, is not included.
+func ReadFile(path string) ([]Package, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM %s: %w", path, err)
+	}
+
+	format, err := DetectFormat(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect format of SBOM %s: %w", path, err)
+	}
+
+	packages, err := Read(content, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM %s: %w", path, err)
+	}
+
+	return packages, nil
+}
+
+// Read reads the packages listed in an SBOM of the given format
+func Read(content []byte, format Format) ([]Package, error) {
+	switch format {
+	case SPDXJSON:
+		return readSPDXJSON(content)
+	case SPDXTagValue:
+		return readSPDXTagValue(content)
+	case CycloneDXJSON:
+		return readCycloneDXJSON(content)
+	case CycloneDXXML:
+		return readCycloneDXXML(content)
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format '%s'", format)
+	}
+}
+
+// DetectFormat guesses the format of an SBOM from its contents
+func DetectFormat(content []byte) (Format, error) {
+	trimmed := bytes.TrimSpace(content)
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var header struct {
+			SPDXVersion string `json:"spdxVersion"`
+			BOMFormat   string `json:"bomFormat"`
+		}
+		if err := json.Unmarshal(trimmed, &header); err != nil {
+			return "", fmt.Errorf("invalid json: %w", err)
+		}
+
+		if header.SPDXVersion != "" {
+			return SPDXJSON, nil
+		}
+		if header.BOMFormat == "CycloneDX" {
+			return CycloneDXJSON, nil
+		}
+		return "", fmt.Errorf("json document is neither SPDX nor CycloneDX")
+
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		if bytes.Contains(trimmed, []byte("cyclonedx.org/schema/bom")) {
+			return CycloneDXXML, nil
+		}
+		return "", fmt.Errorf("xml document is not CycloneDX")
+
+	case bytes.Contains(trimmed, []byte("SPDXVersion:")):
+		return SPDXTagValue, nil
+	}
+
+	return "", fmt.Errorf("unknown SBOM format")
+}
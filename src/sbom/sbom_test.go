@@ -0,0 +1,188 @@
+package sbom_test
+
+import (
+	"testing"
+
+	helpers_test "github.com/eriklarko/license-checker/src/helpers"
+	"github.com/eriklarko/license-checker/src/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spdxJSON = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "my-project",
+  "documentDescribes": ["SPDXRef-Package-my-project"],
+  "packages": [
+    {
+      "SPDXID": "SPDXRef-Package-my-project",
+      "name": "my-project",
+      "versionInfo": "1.0.0",
+      "licenseDeclared": "Apache-2.0"
+    },
+    {
+      "SPDXID": "SPDXRef-Package-left-pad",
+      "name": "left-pad",
+      "versionInfo": "1.3.0",
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "WTFPL OR MIT"
+    },
+    {
+      "SPDXID": "SPDXRef-Package-lodash",
+      "name": "lodash",
+      "versionInfo": "4.17.21",
+      "licenseConcluded": "MIT",
+      "licenseDeclared": "NOASSERTION"
+    },
+    {
+      "SPDXID": "SPDXRef-Package-mystery",
+      "name": "mystery",
+      "versionInfo": "0.0.1"
+    }
+  ]
+}`
+
+const spdxTagValue = `SPDXVersion: SPDX-2.3
+DataLicense: CC0-1.0
+SPDXID: SPDXRef-DOCUMENT
+DocumentName: my-project
+Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-my-project
+
+PackageName: my-project
+SPDXID: SPDXRef-Package-my-project
+PackageLicenseDeclared: Apache-2.0
+
+PackageName: openjdk
+SPDXID: SPDXRef-Package-openjdk
+PackageVersion: 17
+PackageLicenseConcluded: GPL-2.0-only WITH Classpath-exception-2.0
+PackageLicenseComments: <text>This comment
+spans several lines
+PackageName: not-a-package
+</text>
+
+FileName: ./src/main.c
+SPDXID: SPDXRef-File-main
+LicenseConcluded: MIT
+`
+
+const cycloneDXJSON = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {
+    "component": {"type": "application", "name": "my-project", "version": "1.0.0"}
+  },
+  "components": [
+    {
+      "type": "library",
+      "group": "@babel",
+      "name": "core",
+      "version": "7.24.0",
+      "licenses": [{"license": {"id": "MIT"}}]
+    },
+    {
+      "type": "library",
+      "name": "dual-licensed",
+      "version": "2.0.0",
+      "licenses": [{"expression": "MIT OR Apache-2.0"}, {"license": {"name": "Some Custom License"}}],
+      "components": [
+        {"type": "library", "name": "nested", "version": "0.1.0"}
+      ]
+    }
+  ]
+}`
+
+const cycloneDXXML = `<?xml version="1.0" encoding="UTF-8"?>
+<bom xmlns="http://cyclonedx.org/schema/bom/1.5" version="1">
+  <metadata>
+    <component type="application"><name>my-project</name></component>
+  </metadata>
+  <components>
+    <component type="library">
+      <group>org.apache.commons</group>
+      <name>commons-lang3</name>
+      <version>3.14.0</version>
+      <licenses>
+        <license><id>Apache-2.0</id></license>
+      </licenses>
+    </component>
+    <component type="library">
+      <name>expression-licensed</name>
+      <version>1.0</version>
+      <licenses>
+        <expression>BSD-3-Clause OR MIT</expression>
+      </licenses>
+    </component>
+  </components>
+</bom>`
+
+func TestReadFile(t *testing.T) {
+	tests := map[string]struct {
+		content          string
+		expectedPackages []sbom.Package
+	}{
+		"SPDX json": {
+			content: spdxJSON,
+			expectedPackages: []sbom.Package{
+				{Name: "left-pad", Version: "1.3.0", License: "WTFPL OR MIT"},
+				{Name: "lodash", Version: "4.17.21", License: "MIT"},
+				{Name: "mystery", Version: "0.0.1", License: sbom.NoAssertion},
+			},
+		},
+		"SPDX tag-value": {
+			content: spdxTagValue,
+			expectedPackages: []sbom.Package{
+				{Name: "openjdk", Version: "17", License: "GPL-2.0-only WITH Classpath-exception-2.0"},
+			},
+		},
+		"CycloneDX json": {
+			content: cycloneDXJSON,
+			expectedPackages: []sbom.Package{
+				{Name: "@babel/core", Version: "7.24.0", License: "MIT"},
+				{Name: "dual-licensed", Version: "2.0.0", License: "(MIT OR Apache-2.0) AND LicenseRef-Some-Custom-License"},
+				{Name: "nested", Version: "0.1.0", License: sbom.NoAssertion},
+			},
+		},
+		"CycloneDX xml": {
+			content: cycloneDXXML,
+			expectedPackages: []sbom.Package{
+				{Name: "org.apache.commons/commons-lang3", Version: "3.14.0", License: "Apache-2.0"},
+				{Name: "expression-licensed", Version: "1.0", License: "BSD-3-Clause OR MIT"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := helpers_test.CreateTempFileWithContents(t, tt.content)
+
+			packages, err := sbom.ReadFile(path)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedPackages, packages)
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		path := helpers_test.CreateTempFileWithContents(t, `{"hello": "world"}`)
+
+		_, err := sbom.ReadFile(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]sbom.Format{
+		spdxJSON:      sbom.SPDXJSON,
+		spdxTagValue:  sbom.SPDXTagValue,
+		cycloneDXJSON: sbom.CycloneDXJSON,
+		cycloneDXXML:  sbom.CycloneDXXML,
+	}
+
+	for content, expectedFormat := range tests {
+		format, err := sbom.DetectFormat([]byte(content))
+		require.NoError(t, err)
+		assert.Equal(t, expectedFormat, format)
+	}
+}
@@ -0,0 +1,179 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The SPDX ID of the document itself, used in relationships to say which
+// packages the document describes
+const spdxDocumentID = "SPDXRef-DOCUMENT"
+
+// spdxDocument is the subset of an SPDX 2.3 JSON document this tool cares
+// about. See https://spdx.github.io/spdx-spec/v2.3/
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense,omitempty"`
+	SPDXID            string             `json:"SPDXID,omitempty"`
+	Name              string             `json:"name,omitempty"`
+	DocumentNamespace string             `json:"documentNamespace,omitempty"`
+	CreationInfo      *spdxCreationInfo  `json:"creationInfo,omitempty"`
+	DocumentDescribes []string           `json:"documentDescribes,omitempty"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string           `json:"SPDXID"`
+	Name             string           `json:"name"`
+	VersionInfo      string           `json:"versionInfo,omitempty"`
+	DownloadLocation string           `json:"downloadLocation,omitempty"`
+	FilesAnalyzed    *bool            `json:"filesAnalyzed,omitempty"`
+	LicenseConcluded string           `json:"licenseConcluded,omitempty"`
+	LicenseDeclared  string           `json:"licenseDeclared,omitempty"`
+	Annotations      []spdxAnnotation `json:"annotations,omitempty"`
+}
+
+type spdxAnnotation struct {
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Annotator      string `json:"annotator"`
+	Comment        string `json:"comment"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func readSPDXJSON(content []byte) ([]Package, error) {
+	var document spdxDocument
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("invalid SPDX json: %w", err)
+	}
+
+	return document.toPackages(), nil
+}
+
+// toPackages returns all packages in the document, except the ones the
+// document describes
+func (d *spdxDocument) toPackages() []Package {
+	described := make(map[string]bool)
+	for _, id := range d.DocumentDescribes {
+		described[id] = true
+	}
+	for _, relationship := range d.Relationships {
+		if relationship.SPDXElementID == spdxDocumentID && relationship.RelationshipType == "DESCRIBES" {
+			described[relationship.RelatedSPDXElement] = true
+		}
+	}
+
+	var packages []Package
+	for _, p := range d.Packages {
+		if described[p.SPDXID] {
+			continue
+		}
+
+		license := NoAssertion
+		if isMeaningful(p.LicenseConcluded) {
+			license = p.LicenseConcluded
+		} else if isMeaningful(p.LicenseDeclared) {
+			license = p.LicenseDeclared
+		}
+
+		packages = append(packages, Package{
+			Name:    p.Name,
+			Version: p.VersionInfo,
+			License: strings.TrimSpace(license),
+		})
+	}
+
+	return packages
+}
+
+// readSPDXTagValue reads the tag-value flavour of SPDX documents, where each
+// line is a `Tag: value` pair and a package is every tag between one
+// `PackageName` and the next section
+func readSPDXTagValue(content []byte) ([]Package, error) {
+	document := &spdxDocument{}
+	var currentPackage *spdxPackage
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		tag, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected 'Tag: value' but got '%s'", lineNumber, line)
+		}
+		value = strings.TrimSpace(value)
+
+		// multi-line values are wrapped in <text> tags
+		if strings.HasPrefix(value, "<text>") {
+			for !strings.Contains(value, "</text>") && scanner.Scan() {
+				lineNumber++
+				value += "\n" + scanner.Text()
+			}
+			value = strings.TrimSuffix(strings.TrimPrefix(value, "<text>"), "</text>")
+		}
+
+		switch tag {
+		case "SPDXVersion":
+			document.SPDXVersion = value
+		case "PackageName":
+			document.Packages = append(document.Packages, spdxPackage{Name: value})
+			currentPackage = &document.Packages[len(document.Packages)-1]
+		case "FileName", "SnippetSPDXID", "LicenseID":
+			// start of a section that isn't a package
+			currentPackage = nil
+		case "SPDXID":
+			if currentPackage != nil {
+				currentPackage.SPDXID = value
+			}
+		case "PackageVersion":
+			if currentPackage != nil {
+				currentPackage.VersionInfo = value
+			}
+		case "PackageLicenseConcluded":
+			if currentPackage != nil {
+				currentPackage.LicenseConcluded = value
+			}
+		case "PackageLicenseDeclared":
+			if currentPackage != nil {
+				currentPackage.LicenseDeclared = value
+			}
+		case "Relationship":
+			parts := strings.Fields(value)
+			if len(parts) == 3 {
+				document.Relationships = append(document.Relationships, spdxRelationship{
+					SPDXElementID:      parts[0],
+					RelationshipType:   parts[1],
+					RelatedSPDXElement: parts[2],
+				})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read SPDX tag-value document: %w", err)
+	}
+
+	if document.SPDXVersion == "" {
+		return nil, fmt.Errorf("not an SPDX document, SPDXVersion is missing")
+	}
+
+	return document.toPackages(), nil
+}